@@ -0,0 +1,156 @@
+package belog
+
+import (
+	"github.com/pkg/errors"
+	"strings"
+	"sync"
+)
+
+//OverflowPolicy is policy when queue of async logger is full
+type OverflowPolicy int
+
+const (
+	//OverflowPolicyBlock is wait until queue has space
+	OverflowPolicyBlock OverflowPolicy = iota + 1
+	//OverflowPolicyDropNewest is drop new log event
+	OverflowPolicyDropNewest
+	//OverflowPolicyDropOldest is drop the oldest log event in queue
+	OverflowPolicyDropOldest
+)
+
+var (
+	overflowPolicyMap = map[OverflowPolicy]string{
+		OverflowPolicyBlock:      "block",
+		OverflowPolicyDropNewest: "dropNewest",
+		OverflowPolicyDropOldest: "dropOldest",
+	}
+)
+
+//ParseOverflowPolicy is parse name of overflow policy ("block", "dropNewest", "dropOldest")
+func ParseOverflowPolicy(overflowPolicyString string) (overflowPolicy OverflowPolicy, err error) {
+	for overflowPolicy, name := range overflowPolicyMap {
+		if strings.EqualFold(name, strings.TrimSpace(overflowPolicyString)) {
+			return overflowPolicy, nil
+		}
+	}
+	return 0, errors.Errorf("unexpected overflow policy (%v)", overflowPolicyString)
+}
+
+//String is return name of overflow policy
+func (o OverflowPolicy) String() (name string) {
+	name, ok := overflowPolicyMap[o]
+	if !ok {
+		return "unknown"
+	}
+	return name
+}
+
+type asyncEvent struct {
+	loggerName string
+	logEvent   LogEvent
+	flushed    chan struct{}
+}
+
+type asyncQueue struct {
+	events         []*asyncEvent
+	queueSize      int
+	overflowPolicy OverflowPolicy
+	closed         bool
+	stopped        chan struct{}
+	mutex          *sync.Mutex
+	notEmpty       *sync.Cond
+	notFull        *sync.Cond
+}
+
+// push is push log event. pushed is false if queue is closed.
+func (q *asyncQueue) push(loggerName string, logEvent LogEvent) (pushed bool, dropped bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for !q.closed && len(q.events) >= q.queueSize {
+		if q.overflowPolicy == OverflowPolicyDropNewest {
+			return true, true
+		}
+		if q.overflowPolicy == OverflowPolicyDropOldest && q.dropOldest() {
+			dropped = true
+			continue
+		}
+		// block, or queue is filled with flush markers
+		q.notFull.Wait()
+	}
+	if q.closed {
+		return false, dropped
+	}
+	q.events = append(q.events, &asyncEvent{
+		loggerName: loggerName,
+		logEvent:   logEvent,
+	})
+	q.notEmpty.Signal()
+	return true, dropped
+}
+
+func (q *asyncQueue) dropOldest() (dropped bool) {
+	for i, event := range q.events {
+		if event.flushed != nil {
+			continue
+		}
+		q.events = append(q.events[:i], q.events[i+1:]...)
+		return true
+	}
+	return false
+}
+
+// pushFlushMarker is push marker that is closed after all preceding log events are written.
+// It returns nil if queue is closed.
+func (q *asyncQueue) pushFlushMarker() (flushed chan struct{}) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return nil
+	}
+	flushed = make(chan struct{})
+	q.events = append(q.events, &asyncEvent{
+		flushed: flushed,
+	})
+	q.notEmpty.Signal()
+	return flushed
+}
+
+// pop is pop log event. ok is false if queue is closed and empty.
+func (q *asyncQueue) pop() (event *asyncEvent, ok bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for !q.closed && len(q.events) == 0 {
+		q.notEmpty.Wait()
+	}
+	if len(q.events) == 0 {
+		return nil, false
+	}
+	event = q.events[0]
+	q.events[0] = nil
+	q.events = q.events[1:]
+	q.notFull.Signal()
+	return event, true
+}
+
+// close is close queue. remaining log events are written by worker.
+func (q *asyncQueue) close() {
+	q.mutex.Lock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+	q.mutex.Unlock()
+	<-q.stopped
+}
+
+func newAsyncQueue(queueSize int, overflowPolicy OverflowPolicy) (queue *asyncQueue) {
+	mutex := new(sync.Mutex)
+	return &asyncQueue{
+		events:         make([]*asyncEvent, 0, queueSize),
+		queueSize:      queueSize,
+		overflowPolicy: overflowPolicy,
+		stopped:        make(chan struct{}),
+		mutex:          mutex,
+		notEmpty:       sync.NewCond(mutex),
+		notFull:        sync.NewCond(mutex),
+	}
+}
@@ -0,0 +1,130 @@
+package belog
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type blockingHandler struct {
+	written []string
+	blocked chan struct{}
+	mutex   *sync.Mutex
+}
+
+func (h *blockingHandler) IsOpened() (bool) {
+	return true
+}
+
+func (h *blockingHandler) Open() {
+}
+
+func (h *blockingHandler) Write(loggerName string, logEvent LogEvent, formattedLog string) {
+	<-h.blocked
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.written = append(h.written, formattedLog)
+}
+
+func (h *blockingHandler) Flush() {
+}
+
+func (h *blockingHandler) Close() {
+}
+
+func (h *blockingHandler) getWritten() (written []string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]string{}, h.written...)
+}
+
+func newBlockingHandler() (handler *blockingHandler) {
+	return &blockingHandler{
+		blocked: make(chan struct{}),
+		mutex:   new(sync.Mutex),
+	}
+}
+
+func setupAsyncLogger(t *testing.T, name string, overflowPolicy OverflowPolicy) (handler *blockingHandler) {
+	formatter := NewStandardFormatter()
+	formatter.SetAppendNewLine(false)
+	formatter.SetLayout("%(message)")
+	handler = newBlockingHandler()
+	if err := SetLogger(name, NewLogLevelFilter(), formatter, []Handler{handler}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := GetLogger(name).ChangeAsync(2, overflowPolicy); err != nil {
+		t.Errorf("%+v", err)
+	}
+	return handler
+}
+
+func TestAsyncDropNewest(t *testing.T) {
+	handler := setupAsyncLogger(t, "asyncDropNewest", OverflowPolicyDropNewest)
+	logger := GetLogger("asyncDropNewest")
+	logger.Info("1")
+	// wait for worker to pop 1
+	time.Sleep(100 * time.Millisecond)
+	logger.Info("2")
+	logger.Info("3")
+	logger.Info("4")
+	close(handler.blocked)
+	logger.Flush()
+	written := handler.getWritten()
+	if len(written) != 3 || written[0] != "1" || written[1] != "2" || written[2] != "3" {
+		t.Errorf("written mismatch (%v)", written)
+	}
+	if dropped := logger.Statistics().Dropped; dropped != 1 {
+		t.Errorf("dropped mismatch (%v)", dropped)
+	}
+}
+
+func TestAsyncDropOldest(t *testing.T) {
+	handler := setupAsyncLogger(t, "asyncDropOldest", OverflowPolicyDropOldest)
+	logger := GetLogger("asyncDropOldest")
+	logger.Info("1")
+	time.Sleep(100 * time.Millisecond)
+	logger.Info("2")
+	logger.Info("3")
+	logger.Info("4")
+	close(handler.blocked)
+	logger.Flush()
+	written := handler.getWritten()
+	if len(written) != 3 || written[0] != "1" || written[1] != "3" || written[2] != "4" {
+		t.Errorf("written mismatch (%v)", written)
+	}
+	if dropped := logger.Statistics().Dropped; dropped != 1 {
+		t.Errorf("dropped mismatch (%v)", dropped)
+	}
+}
+
+func TestAsyncBlock(t *testing.T) {
+	handler := setupAsyncLogger(t, "asyncBlock", OverflowPolicyBlock)
+	logger := GetLogger("asyncBlock")
+	done := make(chan struct{})
+	go func() {
+		for _, message := range []string{"1", "2", "3", "4"} {
+			logger.Info(message)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Errorf("logging is not blocked")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(handler.blocked)
+	<-done
+	logger.Flush()
+	written := handler.getWritten()
+	if len(written) != 4 {
+		t.Errorf("written mismatch (%v)", written)
+	}
+	if err := logger.ChangeAsync(0, 0); err != nil {
+		t.Errorf("%+v", err)
+	}
+	logger.Info("5")
+	if written := handler.getWritten(); len(written) != 5 {
+		t.Errorf("written mismatch (%v)", written)
+	}
+}
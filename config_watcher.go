@@ -0,0 +1,272 @@
+package belog
+
+import (
+	"github.com/pkg/errors"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var (
+	configWatchers      = make(map[*ConfigWatcher]struct{})
+	configWatchersMutex = new(sync.Mutex)
+)
+
+//ConfigWatcher is watcher of configuration file.
+//It reloads configuration file when the file is changed or reload signal is received.
+type ConfigWatcher struct {
+	configFilePath string
+	interval       time.Duration
+	signals        []os.Signal
+	modTime        time.Time
+	size           int64
+	config         *ConfigLoggers
+	handlers       map[string][]Handler
+	sharedHandlers map[string]Handler
+	stop           chan struct{}
+	stopped        chan struct{}
+	replaceAll     bool
+	stopOnce       *sync.Once
+	reloadMutex    *sync.Mutex
+}
+
+//Reload is reload configuration file.
+//Loggers are changed only when new configuration is valid.
+//Handlers whose configuration is not changed are kept opened.
+func (w *ConfigWatcher) Reload() (err error) {
+	w.reloadMutex.Lock()
+	defer w.reloadMutex.Unlock()
+	fileInfo, err := os.Stat(w.configFilePath)
+	if err != nil {
+		return err
+	}
+	configLoggers, err := decodeConfigFile(w.configFilePath)
+	if err != nil {
+		return err
+	}
+	loggerSettings, shared, err := buildLoggers(configLoggers)
+	if err != nil {
+		return err
+	}
+	// configuration is compared after expanding environment variables, because handlers depend on their values
+	expanded := expandConfigLoggers(configLoggers)
+	w.reuseSharedHandlers(expanded, loggerSettings, shared)
+	for name, setting := range loggerSettings {
+		w.reuseHandlers(name, expanded.Loggers[name], setting)
+	}
+	if err := applyLoggers(loggerSettings, w.replaceAll); err != nil {
+		return err
+	}
+	// file is retried on next poll until it is applied
+	w.modTime = fileInfo.ModTime()
+	w.size = fileInfo.Size()
+	w.config = expanded
+	w.handlers = make(map[string][]Handler)
+	for name, setting := range loggerSettings {
+		w.handlers[name] = setting.handlers
+	}
+	w.sharedHandlers = shared.handlers
+	return nil
+}
+
+// expandConfigLoggers is copy configuration with environment variables expanded
+func expandConfigLoggers(configLoggers *ConfigLoggers) (expanded *ConfigLoggers) {
+	expanded = &ConfigLoggers{
+		Loggers: make(map[string]configLogger, len(configLoggers.Loggers)),
+	}
+	if configLoggers.Handlers != nil {
+		expanded.Handlers = make(map[string]*configStruct, len(configLoggers.Handlers))
+		for id, handlerConfig := range configLoggers.Handlers {
+			expanded.Handlers[id] = expandConfigStruct(handlerConfig)
+		}
+	}
+	for name, loggerConfig := range configLoggers.Loggers {
+		handlers := make([]*configStruct, 0, len(loggerConfig.Handlers))
+		for _, handlerConfig := range loggerConfig.Handlers {
+			handlers = append(handlers, expandConfigStruct(handlerConfig))
+		}
+		loggerConfig.Handlers = handlers
+		expanded.Loggers[name] = loggerConfig
+	}
+	return expanded
+}
+
+func expandConfigStruct(config *configStruct) (expanded *configStruct) {
+	if config == nil {
+		return nil
+	}
+	expanded = &configStruct{
+		Ref:        config.Ref,
+		StructName: expandEnv(config.StructName),
+	}
+	for _, structSetter := range config.StructSetters {
+		if structSetter == nil {
+			expanded.StructSetters = append(expanded.StructSetters, nil)
+			continue
+		}
+		expandedSetter := &configStructSetter{SetterName: structSetter.SetterName}
+		for _, setterParam := range structSetter.SetterParams {
+			if setterParam == nil {
+				expandedSetter.SetterParams = append(expandedSetter.SetterParams, nil)
+				continue
+			}
+			expandedSetter.SetterParams = append(expandedSetter.SetterParams, &configSetterParam{
+				Value:     expandEnv(setterParam.Value),
+				Component: expandConfigStruct(setterParam.Component),
+			})
+		}
+		expanded.StructSetters = append(expanded.StructSetters, expandedSetter)
+	}
+	return expanded
+}
+
+// reuseSharedHandlers is replace new shared handlers by shared handlers of previous configuration that have same configuration
+func (w *ConfigWatcher) reuseSharedHandlers(configLoggers *ConfigLoggers, loggerSettings map[string]*loggerSetting, shared *sharedComponents) {
+	if w.config == nil {
+		return
+	}
+	for id, newHandler := range shared.handlers {
+		oldHandler, ok := w.sharedHandlers[id]
+		if !ok || !reflect.DeepEqual(configLoggers.Handlers[id], w.config.Handlers[id]) {
+			continue
+		}
+		if !reflect.TypeOf(newHandler).Comparable() {
+			continue
+		}
+		for _, setting := range loggerSettings {
+			for i, handler := range setting.handlers {
+				if handler == newHandler {
+					setting.handlers[i] = oldHandler
+				}
+			}
+		}
+		shared.handlers[id] = oldHandler
+	}
+}
+
+// reuseHandlers is replace new handlers by handlers of previous configuration that have same configuration
+func (w *ConfigWatcher) reuseHandlers(name string, loggerConfig configLogger, setting *loggerSetting) {
+	if w.config == nil {
+		return
+	}
+	oldConfig, ok := w.config.Loggers[name]
+	if !ok {
+		return
+	}
+	oldHandlers := w.handlers[name]
+	used := make([]bool, len(oldHandlers))
+	for i, handlerConfig := range loggerConfig.Handlers {
+		if handlerConfig.Ref != "" {
+			// shared handler is reused by reuseSharedHandlers
+			continue
+		}
+		for j, oldHandlerConfig := range oldConfig.Handlers {
+			if j >= len(oldHandlers) || used[j] || oldHandlerConfig.Ref != "" {
+				continue
+			}
+			if reflect.DeepEqual(handlerConfig, oldHandlerConfig) {
+				setting.handlers[i] = oldHandlers[j]
+				used[j] = true
+				break
+			}
+		}
+	}
+}
+
+func (w *ConfigWatcher) changed() (changed bool) {
+	w.reloadMutex.Lock()
+	defer w.reloadMutex.Unlock()
+	fileInfo, err := os.Stat(w.configFilePath)
+	if err != nil {
+		return false
+	}
+	return !fileInfo.ModTime().Equal(w.modTime) || fileInfo.Size() != w.size
+}
+
+func (w *ConfigWatcher) watch() {
+	defer close(w.stopped)
+	signalChan := make(chan os.Signal, 1)
+	if len(w.signals) > 0 {
+		signal.Notify(signalChan, w.signals...)
+		defer signal.Stop(signalChan)
+	}
+	var tick <-chan time.Time
+	if w.interval > 0 {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-signalChan:
+		case <-tick:
+			if !w.changed() {
+				continue
+			}
+		}
+		if err := w.Reload(); err != nil {
+			ReportError("", nil, "ConfigWatcher", err)
+		}
+	}
+}
+
+//SetReplaceAll is set whether loggers that are not included in configuration file are removed on reload
+func (w *ConfigWatcher) SetReplaceAll(replaceAll bool) {
+	w.reloadMutex.Lock()
+	defer w.reloadMutex.Unlock()
+	w.replaceAll = replaceAll
+}
+
+//Stop is stop watching configuration file
+func (w *ConfigWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.stopped
+	configWatchersMutex.Lock()
+	defer configWatchersMutex.Unlock()
+	delete(configWatchers, w)
+}
+
+// stopConfigWatchers is stop all config watchers, so that configuration is not reloaded after Shutdown
+func stopConfigWatchers() {
+	configWatchersMutex.Lock()
+	watchers := make([]*ConfigWatcher, 0, len(configWatchers))
+	for watcher := range configWatchers {
+		watchers = append(watchers, watcher)
+	}
+	configWatchersMutex.Unlock()
+	for _, watcher := range watchers {
+		watcher.Stop()
+	}
+}
+
+//WatchConfig is load configuration file and watch it.
+//The file is polled every interval (0 is disabled) and reloaded when it is changed.
+//The file is also reloaded when one of signals (e.g. syscall.SIGHUP) is received.
+func WatchConfig(configFilePath string, interval time.Duration, signals ...os.Signal) (configWatcher *ConfigWatcher, err error) {
+	if interval <= 0 && len(signals) == 0 {
+		return nil, errors.Errorf("invalid argument")
+	}
+	configWatcher = &ConfigWatcher{
+		configFilePath: configFilePath,
+		interval:       interval,
+		signals:        signals,
+		stop:           make(chan struct{}),
+		stopped:        make(chan struct{}),
+		stopOnce:       new(sync.Once),
+		reloadMutex:    new(sync.Mutex),
+	}
+	if err := configWatcher.Reload(); err != nil {
+		return nil, err
+	}
+	configWatchersMutex.Lock()
+	configWatchers[configWatcher] = struct{}{}
+	configWatchersMutex.Unlock()
+	go configWatcher.watch()
+	return configWatcher, nil
+}
@@ -0,0 +1,127 @@
+package belog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const watchConfigTemplate = `{
+	"loggers": {
+		"watch1": {
+			"filter": {
+				"structName": "LogLevelFilter",
+				"structSetters": [{"setterName": "SetLogLevel", "setterParams": ["%v"]}]
+			},
+			"formatter": {"structName": "StandardFormatter"},
+			"handlers": [
+				{
+					"structName": "RotationFileHandler",
+					"structSetters": [
+						{"setterName": "SetLogFileName", "setterParams": ["belog-watch.log"]},
+						{"setterName": "SetLogDirPath", "setterParams": ["/var/tmp/belog-test"]}
+					]
+				}
+			]
+		}
+	}
+}`
+
+func writeWatchConfig(t *testing.T, configFilePath string, logLevel string) {
+	config := []byte(fmt.Sprintf(watchConfigTemplate, logLevel))
+	if err := ioutil.WriteFile(configFilePath, config, 0644); err != nil {
+		t.Fatalf("%+v", err)
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "belog-watch")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+	configFilePath := filepath.Join(dir, "watch.json")
+	writeWatchConfig(t, configFilePath, "8")
+	watcher, err := WatchConfig(configFilePath, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer watcher.Stop()
+	logger := GetLogger("watch1")
+	handler := lookupLogger("watch1").handlers[0]
+	if !logger.IsEnabled(LogLevelDebug) {
+		t.Errorf("debug is not enabled")
+	}
+
+	// invalid config is ignored
+	writeWatchConfig(t, configFilePath, "x")
+	if err := watcher.Reload(); err == nil {
+		t.Errorf("no error")
+	}
+	if !logger.IsEnabled(LogLevelDebug) {
+		t.Errorf("invalid config is applied")
+	}
+	if !watcher.changed() {
+		t.Errorf("invalid config is not retried")
+	}
+
+	// changed file is reloaded by polling
+	writeWatchConfig(t, configFilePath, "7")
+	os.Chtimes(configFilePath, time.Now(), time.Now().Add(time.Second))
+	for i := 0; i < 100 && logger.IsEnabled(LogLevelDebug); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if logger.IsEnabled(LogLevelDebug) {
+		t.Errorf("config is not reloaded")
+	}
+	newHandler := lookupLogger("watch1").handlers[0]
+	if newHandler != handler {
+		t.Errorf("unchanged handler is replaced")
+	}
+	if !newHandler.IsOpened() {
+		t.Errorf("unchanged handler is closed")
+	}
+}
+
+func TestWatchConfigExpandEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "belog-watch")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+	configFilePath := filepath.Join(dir, "watch.json")
+	config := strings.Replace(fmt.Sprintf(watchConfigTemplate, "8"), "/var/tmp/belog-test", "${BELOG_TEST_WATCH_DIR}", 1)
+	if err := ioutil.WriteFile(configFilePath, []byte(config), 0644); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	os.Setenv("BELOG_TEST_WATCH_DIR", "/var/tmp/belog-test")
+	defer os.Unsetenv("BELOG_TEST_WATCH_DIR")
+	watcher, err := WatchConfig(configFilePath, time.Hour)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer watcher.Stop()
+	handler := lookupLogger("watch1").handlers[0]
+	if err := watcher.Reload(); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if lookupLogger("watch1").handlers[0] != handler {
+		t.Errorf("unchanged handler is replaced")
+	}
+
+	// handler is replaced when value of environment variable is changed
+	os.Setenv("BELOG_TEST_WATCH_DIR", "/var/tmp/belog-test/watch")
+	if err := watcher.Reload(); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if lookupLogger("watch1").handlers[0] == handler {
+		t.Errorf("handler of changed environment variable is reused")
+	}
+	if handler.IsOpened() {
+		t.Errorf("old handler is not closed")
+	}
+}
@@ -1,273 +1,660 @@
 package belog
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
+	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"reflect"
-	"strconv"
 	"strings"
+	"sync"
 )
 
 //ConfigLoggers is config of Loggers
 type ConfigLoggers struct {
-	Loggers map[string]configLogger `json:"loggers" yaml:"loggers" toml:"loggers"`
+	Filters    map[string]*configStruct `json:"filters,omitempty"    yaml:"filters,omitempty"    toml:"filters,omitempty"`
+	Formatters map[string]*configStruct `json:"formatters,omitempty" yaml:"formatters,omitempty" toml:"formatters,omitempty"`
+	Handlers   map[string]*configStruct `json:"handlers,omitempty"   yaml:"handlers,omitempty"   toml:"handlers,omitempty"`
+	Loggers    map[string]configLogger  `json:"loggers"              yaml:"loggers"              toml:"loggers"`
 }
 
 type configLogger struct {
-	Filter    *configStruct   `json:"filter"    yaml:"filter"    toml:"filter"`
-	Formatter *configStruct   `json:"formatter" yaml:"formatter" toml:"formatter"`
-	Handlers  []*configStruct `json:"handlers"  yaml:"handlers"  toml:"handlers"`
+	Filter             *configStruct   `json:"filter"             yaml:"filter"             toml:"filter"`
+	Formatter          *configStruct   `json:"formatter"          yaml:"formatter"          toml:"formatter"`
+	Handlers           []*configStruct `json:"handlers"           yaml:"handlers"           toml:"handlers"`
+	StackTraceLogLevel string          `json:"stackTraceLogLevel,omitempty" yaml:"stackTraceLogLevel,omitempty" toml:"stackTraceLogLevel,omitempty"`
+	Async              *configAsync    `json:"async,omitempty"              yaml:"async,omitempty"              toml:"async,omitempty"`
+}
+
+type configAsync struct {
+	QueueSize      int    `json:"queueSize"      yaml:"queueSize"      toml:"queueSize"`
+	OverflowPolicy string `json:"overflowPolicy" yaml:"overflowPolicy" toml:"overflowPolicy"`
 }
 
 type configStruct struct {
+	Ref           string                `json:"ref,omitempty" yaml:"ref,omitempty" toml:"ref,omitempty"`
 	StructName    string                `json:"structName"    yaml:"structName"    toml:"structName"`
 	StructSetters []*configStructSetter `json:"structSetters" yaml:"structSetters" toml:"structSetters"`
 }
 
 type configStructSetter struct {
-	SetterName   string   `json:"setterName"   yaml:"setterName"   toml:"setterName"`
-	SetterParams []string `json:"setterParams" yaml:"setterParams" toml:"setterParams"`
+	SetterName   string               `json:"setterName"   yaml:"setterName"   toml:"setterName"`
+	SetterParams []*configSetterParam `json:"setterParams" yaml:"setterParams" toml:"setterParams"`
+}
+
+// configSetterParam is string value or nested component definition
+type configSetterParam struct {
+	Value     string
+	Component *configStruct
+}
+
+func (p *configSetterParam) UnmarshalJSON(data []byte) (err error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		p.Component = new(configStruct)
+		return json.Unmarshal(data, p.Component)
+	}
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &p.Value)
+	}
+	// number or bool
+	p.Value = string(data)
+	return nil
+}
+
+func (p *configSetterParam) MarshalJSON() (data []byte, err error) {
+	if p.Component != nil {
+		return json.Marshal(p.Component)
+	}
+	return json.Marshal(p.Value)
+}
+
+func (p *configSetterParam) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	if err := unmarshal(&p.Value); err == nil {
+		return nil
+	}
+	p.Component = new(configStruct)
+	return unmarshal(p.Component)
+}
+
+func (p *configSetterParam) MarshalYAML() (value interface{}, err error) {
+	if p.Component != nil {
+		return p.Component, nil
+	}
+	return p.Value, nil
+}
+
+func (p *configSetterParam) MarshalTOML() (data []byte, err error) {
+	var buffer bytes.Buffer
+	if err := writeTOMLSetterParam(&buffer, p); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (p *configSetterParam) UnmarshalTOML(value interface{}) (err error) {
+	table, ok := value.(map[string]interface{})
+	if !ok {
+		p.Value = fmt.Sprint(value)
+		return nil
+	}
+	// decode table through json, field names are same in all formats
+	data, err := json.Marshal(table)
+	if err != nil {
+		return err
+	}
+	p.Component = new(configStruct)
+	return json.Unmarshal(data, p.Component)
+}
+
+// sharedComponents is components defined in top level of config, they are referenced by id from loggers
+type sharedComponents struct {
+	filters    map[string]Filter
+	formatters map[string]Formatter
+	handlers   map[string]Handler
+}
+
+type loggerSetting struct {
+	filter              Filter
+	formatter           Formatter
+	handlers            []Handler
+	stackTraceLogLevel  LogLevel
+	asyncQueueSize      int
+	asyncOverflowPolicy OverflowPolicy
 }
 
 //LoadConfig is load configration file
 func LoadConfig(configFilePath string) (err error) {
-	configLoggers := new(ConfigLoggers)
+	configLoggers, err := decodeConfigFile(configFilePath)
+	if err != nil {
+		return err
+	}
+	return SetupLoggers(configLoggers)
+}
+
+//LoadConfigFrom is load configuration from reader. format is "toml", "yaml" or "json".
+func LoadConfigFrom(reader io.Reader, format string) (err error) {
+	configLoggers, err := DecodeConfig(reader, format)
+	if err != nil {
+		return err
+	}
+	return SetupLoggers(configLoggers)
+}
+
+//DecodeConfig is decode configuration from reader. format is "toml", "yaml" or "json".
+func DecodeConfig(reader io.Reader, format string) (configLoggers *ConfigLoggers, err error) {
+	buf, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(buf, format)
+}
+
+func decodeConfigFile(configFilePath string) (configLoggers *ConfigLoggers, err error) {
 	ext := filepath.Ext(configFilePath)
-	switch ext {
-	case ".tml":
-		fallthrough
-	case ".toml":
-		_, err := toml.DecodeFile(configFilePath, configLoggers)
-		if err != nil {
-			return err
-		}
-	case ".yml":
-		fallthrough
-	case ".yaml":
-		buf, err := ioutil.ReadFile(configFilePath)
+	if _, err := configFormat(ext); err != nil {
+		return nil, errors.Errorf("unexpected file extension (%v)", ext)
+	}
+	buf, err := ioutil.ReadFile(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(buf, ext)
+}
+
+// configFormat is normalize format name or file extension to "toml", "yaml" or "json"
+func configFormat(format string) (normalized string, err error) {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "tml", "toml":
+		return "toml", nil
+	case "yml", "yaml":
+		return "yaml", nil
+	case "jsn", "json":
+		return "json", nil
+	default:
+		return "", errors.Errorf("unexpected format (%v)", format)
+	}
+}
+
+func decodeConfig(buf []byte, format string) (configLoggers *ConfigLoggers, err error) {
+	format, err = configFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	configLoggers = new(ConfigLoggers)
+	switch format {
+	case "toml":
+		_, err := toml.Decode(string(buf), configLoggers)
 		if err != nil {
-			return err
+			return nil, err
 		}
+	case "yaml":
 		err = yaml.Unmarshal(buf, configLoggers)
 		if err != nil {
-			return err
-		}
-	case ".jsn":
-		fallthrough
-	case ".json":
-		buf, err := ioutil.ReadFile(configFilePath)
-		if err != nil {
-			return err
+			return nil, err
 		}
+	case "json":
 		err = json.Unmarshal(buf, configLoggers)
 		if err != nil {
-			return err
+			return nil, jsonError(buf, err)
+		}
+	}
+	return configLoggers, nil
+}
+
+//MergeConfig is merge override into base and return new ConfigLoggers.
+//Loggers and shared components of override replace ones of base that have same name.
+func MergeConfig(base *ConfigLoggers, override *ConfigLoggers) (merged *ConfigLoggers) {
+	merged = &ConfigLoggers{
+		Filters:    make(map[string]*configStruct),
+		Formatters: make(map[string]*configStruct),
+		Handlers:   make(map[string]*configStruct),
+		Loggers:    make(map[string]configLogger),
+	}
+	for _, configLoggers := range []*ConfigLoggers{base, override} {
+		if configLoggers == nil {
+			continue
+		}
+		for id, configStruct := range configLoggers.Filters {
+			merged.Filters[id] = configStruct
+		}
+		for id, configStruct := range configLoggers.Formatters {
+			merged.Formatters[id] = configStruct
+		}
+		for id, configStruct := range configLoggers.Handlers {
+			merged.Handlers[id] = configStruct
 		}
+		for name, loggerConfig := range configLoggers.Loggers {
+			merged.Loggers[name] = loggerConfig
+		}
+	}
+	return merged
+}
+
+// jsonError is add line number to error of json decoder
+func jsonError(buf []byte, err error) (error) {
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
 	default:
-		return errors.Errorf("unexpected file extension (%v)", ext)
+		return err
 	}
-	return SetupLoggers(configLoggers)
+	if offset > int64(len(buf)) {
+		offset = int64(len(buf))
+	}
+	line := bytes.Count(buf[:offset], []byte("\n")) + 1
+	return errors.Errorf("json: line %v: %v", line, err)
 }
 
 // SetupLoggers is setup from configLoggets
 func SetupLoggers(configLoggers *ConfigLoggers) (error) {
-	return setupLoggersBase(configLoggers, false)
+	return setupLoggersBase(configLoggers, false, false)
 }
 
-// ValidateLoggers is setup from configLoggets
+// ReplaceLoggers is setup from configLoggers and remove loggers that are not included in configLoggers
+func ReplaceLoggers(configLoggers *ConfigLoggers) (error) {
+	return setupLoggersBase(configLoggers, false, true)
+}
+
+// ValidateLoggers is validate configLoggers without side effect.
+// setters and Open of components are not called. It returns ConfigErrors including all problems.
 func ValidateLoggers(configLoggers *ConfigLoggers) (error) {
-	return setupLoggersBase(configLoggers, true)
+	return setupLoggersBase(configLoggers, true, false)
+}
+
+func setupLoggersBase(configLoggers *ConfigLoggers, dryrun bool, replaceAll bool) (err error) {
+	if dryrun {
+		return validateConfig(configLoggers)
+	}
+	loggerSettings, _, err := buildLoggers(configLoggers)
+	if err != nil {
+		return err
+	}
+	return applyLoggers(loggerSettings, replaceAll)
 }
 
-func setupLoggersBase(configLoggers *ConfigLoggers, dryrun bool) (err error) {
-	tmpLoggers := make(map[string]*logger)
-	if configLoggers == nil {
-		return errors.Errorf("empty config")
+func buildSharedComponents(configLoggers *ConfigLoggers) (shared *sharedComponents, err error) {
+	shared = &sharedComponents{
+		filters:    make(map[string]Filter),
+		formatters: make(map[string]Formatter),
+		handlers:   make(map[string]Handler),
+	}
+	for id, configStruct := range configLoggers.Filters {
+		if configStruct == nil || configStruct.Ref != "" {
+			return nil, errors.Errorf("invalid shared filter (%v)", id)
+		}
+		filter, err := getFilter(expandEnv(configStruct.StructName))
+		if err != nil {
+			return nil, errors.Errorf("not found filter (%v)", configStruct.StructName)
+		}
+		if err = setupInstance(filter, configStruct, "filters."+id); err != nil {
+			return nil, err
+		}
+		shared.filters[id] = filter
+	}
+	for id, configStruct := range configLoggers.Formatters {
+		if configStruct == nil || configStruct.Ref != "" {
+			return nil, errors.Errorf("invalid shared formatter (%v)", id)
+		}
+		formatter, err := getFormatter(expandEnv(configStruct.StructName))
+		if err != nil {
+			return nil, errors.Errorf("not found formatter (%v)", configStruct.StructName)
+		}
+		if err = setupInstance(formatter, configStruct, "formatters."+id); err != nil {
+			return nil, err
+		}
+		shared.formatters[id] = formatter
+	}
+	for id, configStruct := range configLoggers.Handlers {
+		if configStruct == nil || configStruct.Ref != "" {
+			return nil, errors.Errorf("invalid shared handler (%v)", id)
+		}
+		handler, err := getHandler(expandEnv(configStruct.StructName))
+		if err != nil {
+			return nil, errors.Errorf("not found handler (%v)", configStruct.StructName)
+		}
+		if err = setupInstance(handler, configStruct, "handlers."+id); err != nil {
+			return nil, err
+		}
+		shared.handlers[id] = handler
+	}
+	return shared, nil
+}
+
+func buildLoggers(configLoggers *ConfigLoggers) (loggerSettings map[string]*loggerSetting, shared *sharedComponents, err error) {
+	if err := validateConfig(configLoggers); err != nil {
+		return nil, nil, err
+	}
+	loggerSettings = make(map[string]*loggerSetting)
+	shared, err = buildSharedComponents(configLoggers)
+	if err != nil {
+		return nil, nil, err
 	}
 	for name, loggerConfig := range configLoggers.Loggers {
 		// get filter
 		if loggerConfig.Filter == nil {
-			return errors.Errorf("no filter")
-		}
-		filter, err := getFilter(loggerConfig.Filter.StructName)
-		if err != nil {
-			return errors.Errorf("not found filter (%v)", loggerConfig.Filter.StructName)
+			return nil, nil, errors.Errorf("no filter")
 		}
-		// setup filter
-		if err = setupInstance(filter, loggerConfig.Filter); err != nil {
-			return err
+		var filter Filter
+		if loggerConfig.Filter.Ref != "" {
+			var ok bool
+			if filter, ok = shared.filters[loggerConfig.Filter.Ref]; !ok {
+				return nil, nil, errors.Errorf("not found shared filter (%v)", loggerConfig.Filter.Ref)
+			}
+		} else {
+			filter, err = getFilter(expandEnv(loggerConfig.Filter.StructName))
+			if err != nil {
+				return nil, nil, errors.Errorf("not found filter (%v)", loggerConfig.Filter.StructName)
+			}
+			// setup filter
+			if err = setupInstance(filter, loggerConfig.Filter, "loggers."+name+".filter"); err != nil {
+				return nil, nil, err
+			}
 		}
 		// get formatter
 		if loggerConfig.Formatter == nil {
-			return errors.Errorf("no formatter")
-		}
-		formatter, err := getFormatter(loggerConfig.Formatter.StructName)
-		if err != nil {
-			return errors.Errorf("not found formatter (%v)", loggerConfig.Formatter.StructName)
+			return nil, nil, errors.Errorf("no formatter")
 		}
-		// setup formatter
-		if err = setupInstance(formatter, loggerConfig.Formatter); err != nil {
-			return err
+		var formatter Formatter
+		if loggerConfig.Formatter.Ref != "" {
+			var ok bool
+			if formatter, ok = shared.formatters[loggerConfig.Formatter.Ref]; !ok {
+				return nil, nil, errors.Errorf("not found shared formatter (%v)", loggerConfig.Formatter.Ref)
+			}
+		} else {
+			formatter, err = getFormatter(expandEnv(loggerConfig.Formatter.StructName))
+			if err != nil {
+				return nil, nil, errors.Errorf("not found formatter (%v)", loggerConfig.Formatter.StructName)
+			}
+			// setup formatter
+			if err = setupInstance(formatter, loggerConfig.Formatter, "loggers."+name+".formatter"); err != nil {
+				return nil, nil, err
+			}
 		}
 		// check handlers
 		if loggerConfig.Handlers == nil {
-			return errors.Errorf("no handlers")
+			return nil, nil, errors.Errorf("no handlers")
 		}
 		handlers := make([]Handler, 0, 1)
-		for _, configStruct := range loggerConfig.Handlers {
+		for i, configStruct := range loggerConfig.Handlers {
+			if configStruct.Ref != "" {
+				handler, ok := shared.handlers[configStruct.Ref]
+				if !ok {
+					return nil, nil, errors.Errorf("not found shared handler (%v)", configStruct.Ref)
+				}
+				handlers = append(handlers, handler)
+				continue
+			}
 			// get handler
-			handler, err := getHandler(configStruct.StructName)
+			handler, err := getHandler(expandEnv(configStruct.StructName))
 			if err != nil {
-				return errors.Errorf("not found handler (%v)", configStruct.StructName)
+				return nil, nil, errors.Errorf("not found handler (%v)", configStruct.StructName)
 			}
 			// setup formatter
-			if err = setupInstance(handler, configStruct); err != nil {
-				return err
+			if err = setupInstance(handler, configStruct, fmt.Sprintf("loggers.%v.handlers[%d]", name, i)); err != nil {
+				return nil, nil, err
 			}
 			handlers = append(handlers, handler)
 		}
-		newLogger := &logger{
-			filter:    filter,
-			formatter: formatter,
-			handlers:  handlers,
+		// get stack trace log level
+		var stackTraceLogLevel LogLevel
+		if loggerConfig.StackTraceLogLevel != "" {
+			stackTraceLogLevel, err = ParseLogLevel(loggerConfig.StackTraceLogLevel)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		// get async
+		asyncQueueSize := 0
+		asyncOverflowPolicy := OverflowPolicyBlock
+		if loggerConfig.Async != nil && loggerConfig.Async.QueueSize > 0 {
+			asyncQueueSize = loggerConfig.Async.QueueSize
+			if loggerConfig.Async.OverflowPolicy != "" {
+				asyncOverflowPolicy, err = ParseOverflowPolicy(loggerConfig.Async.OverflowPolicy)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+		loggerSettings[name] = &loggerSetting{
+			filter:              filter,
+			formatter:           formatter,
+			handlers:            handlers,
+			stackTraceLogLevel:  stackTraceLogLevel,
+			asyncQueueSize:      asyncQueueSize,
+			asyncOverflowPolicy: asyncOverflowPolicy,
 		}
-		tmpLoggers[name] = newLogger
 	}
-	if dryrun {
-		return nil
+	return loggerSettings, shared, nil
+}
+
+func (s *loggerSetting) validate() (err error) {
+	if s.filter == nil || s.formatter == nil || s.handlers == nil || len(s.handlers) == 0 {
+		return errors.Errorf("invalid argument")
 	}
-	for name, newLogger := range tmpLoggers {
-		err := SetLogger(name, newLogger.filter, newLogger.formatter, newLogger.handlers)
-		if err != nil {
-			return err
+	if s.stackTraceLogLevel < 0 || s.stackTraceLogLevel > LogLevelTrace {
+		return errors.Errorf("invalid stack trace log level (%v)", s.stackTraceLogLevel)
+	}
+	if s.asyncQueueSize > 0 {
+		if _, ok := overflowPolicyMap[s.asyncOverflowPolicy]; !ok {
+			return errors.Errorf("invalid overflow policy (%v)", s.asyncOverflowPolicy)
+		}
+	}
+	return nil
+}
+
+// applyLoggers is apply all logger settings at once. nothing is changed if any setting is invalid.
+// loggers that are not included in loggerSettings are removed when replaceAll is true.
+func applyLoggers(loggerSettings map[string]*loggerSetting, replaceAll bool) (err error) {
+	for name, setting := range loggerSettings {
+		if err := setting.validate(); err != nil {
+			return errors.Errorf("invalid setting of logger (%v): %v", name, err)
+		}
+	}
+	for _, setting := range loggerSettings {
+		acquireHandlers(setting.handlers)
+	}
+	oldHandlers := make([][]Handler, 0, len(loggerSettings))
+	oldQueues := make([]*asyncQueue, 0)
+	removedLoggers := make([]*logger, 0)
+	loggersMutex.Lock()
+	for name, setting := range loggerSettings {
+		l := defaultLogger
+		if name != "default" {
+			l = loggers[name]
+		}
+		if l == nil {
+			l = &logger{
+				counters: new(loggerCounters),
+				mutex:    new(sync.RWMutex),
+			}
+			loggers[name] = l
+		}
+		l.mutex.Lock()
+		if !l.closed && l.handlers != nil {
+			oldHandlers = append(oldHandlers, l.handlers)
+		}
+		l.filter = setting.filter
+		l.formatter = setting.formatter
+		l.handlers = setting.handlers
+		l.stackTraceLogLevel = setting.stackTraceLogLevel
+		l.closed = false
+		if oldQueue := l.swapQueue(setting.asyncQueueSize, setting.asyncOverflowPolicy); oldQueue != nil {
+			oldQueues = append(oldQueues, oldQueue)
+		}
+		l.mutex.Unlock()
+	}
+	if replaceAll {
+		for name, l := range loggers {
+			if _, ok := loggerSettings[name]; !ok {
+				delete(loggers, name)
+				removedLoggers = append(removedLoggers, l)
+			}
 		}
 	}
+	loggersMutex.Unlock()
+	// close handlers that are no longer used
+	for _, handlers := range oldHandlers {
+		releaseHandlers(handlers)
+	}
+	for _, queue := range oldQueues {
+		queue.close()
+	}
+	for _, l := range removedLoggers {
+		l.close()
+	}
 	return nil
 }
 
-func setupInstance(instance interface{}, configStruct *configStruct) (err error) {
-	for _, structSetter := range configStruct.StructSetters {
+func setupInstance(instance interface{}, configStruct *configStruct, path string) (err error) {
+	for j, structSetter := range configStruct.StructSetters {
+		setterPath := fmt.Sprintf("%v.structSetters[%d]", path, j)
 		instanceValue := reflect.ValueOf(instance)
 		methodValue := instanceValue.MethodByName(strings.TrimSpace(structSetter.SetterName))
 		if !methodValue.IsValid() {
-			return errors.Errorf("unexpected Method (%v)", structSetter.SetterName)
+			return newConfigError(setterPath, errors.Errorf("unexpected Method (%v)", structSetter.SetterName))
 		}
 		methodType := methodValue.Type()
 		argsNum := methodType.NumIn()
 		if len(structSetter.SetterParams) != argsNum {
-			return errors.Errorf("parameter count mismatch of setter method (%v: exp %v != act %v)", structSetter.SetterName, argsNum, len(structSetter.SetterParams))
+			return newConfigError(setterPath, errors.Errorf("parameter count mismatch of setter method (%v: exp %v != act %v)", structSetter.SetterName, argsNum, len(structSetter.SetterParams)))
 		}
 		outNum := methodType.NumOut()
 		if outNum > 1 {
-			return errors.Errorf("return value is too many of setter method")
+			return newConfigError(setterPath, errors.Errorf("return value is too many of setter method"))
 		}
 		methodArgs := make([]reflect.Value, 0, argsNum)
 		for i, setterParam := range structSetter.SetterParams {
-			argType := methodType.In(i)
+			paramPath := fmt.Sprintf("%v.setterParams[%d]", setterPath, i)
+			if setterParam == nil {
+				return newConfigError(paramPath, errors.Errorf("empty setter parameter (%v)", structSetter.SetterName))
+			}
 			var reflectValue reflect.Value
-			switch argType.Kind() {
-			case reflect.Bool:
-				val, err := strconv.ParseBool(setterParam)
-				if err != nil {
-					return err
-				}
-				reflectValue = reflect.ValueOf(val)
-			case reflect.Int:
-				val, err := strconv.ParseInt(setterParam, 10, 0)
-				if err != nil {
-					return err
-				}
-				reflectValue = reflect.ValueOf(int(val))
-			case reflect.Int8:
-				val, err := strconv.ParseInt(setterParam, 10, 8)
-				if err != nil {
-					return err
-				}
-				reflectValue = reflect.ValueOf(int8(val))
-			case reflect.Int16:
-				val, err := strconv.ParseInt(setterParam, 10, 16)
-				if err != nil {
-					return err
-				}
-				reflectValue = reflect.ValueOf(int16(val))
-			case reflect.Int32:
-				val, err := strconv.ParseInt(setterParam, 10, 32)
-				if err != nil {
-					return err
-				}
-				reflectValue = reflect.ValueOf(int32(val))
-			case reflect.Int64:
-				val, err := strconv.ParseInt(setterParam, 10, 64)
-				if err != nil {
-					return err
-				}
-				reflectValue = reflect.ValueOf(val)
-			case reflect.Uint:
-				val, err := strconv.ParseUint(setterParam, 10, 0)
-				if err != nil {
-					return err
-				}
-				reflectValue = reflect.ValueOf(uint(val))
-			case reflect.Uint8:
-				val, err := strconv.ParseUint(setterParam, 10, 8)
-				if err != nil {
-					return err
-				}
-				reflectValue = reflect.ValueOf(uint8(val))
-			case reflect.Uint16:
-				val, err := strconv.ParseUint(setterParam, 10, 16)
-				if err != nil {
-					return err
-				}
-				reflectValue = reflect.ValueOf(uint16(val))
-			case reflect.Uint32:
-				val, err := strconv.ParseUint(setterParam, 10, 32)
-				if err != nil {
-					return err
-				}
-				reflectValue = reflect.ValueOf(uint32(val))
-			case reflect.Uint64:
-				val, err := strconv.ParseUint(setterParam, 10, 64)
-				if err != nil {
-					return err
-				}
-				reflectValue = reflect.ValueOf(val)
-			case reflect.Float32:
-				val, err := strconv.ParseFloat(setterParam, 32)
+			if setterParam.Component != nil {
+				reflectValue, err = setupComponent(setterParam.Component, methodType.In(i), paramPath)
 				if err != nil {
 					return err
 				}
-				reflectValue = reflect.ValueOf(float32(val))
-			case reflect.Float64:
-				val, err := strconv.ParseFloat(setterParam, 64)
+			} else {
+				reflectValue, err = parseSetterParam(expandEnv(setterParam.Value), methodType.In(i))
 				if err != nil {
-					return err
+					return newConfigError(paramPath, err)
 				}
-				reflectValue = reflect.ValueOf(val)
-			case reflect.String:
-				reflectValue = reflect.ValueOf(setterParam)
-			default:
-				return errors.Errorf("unsupported kind of setter paramter (%v)", argType.Kind())
 			}
-			methodArgs = append(methodArgs, reflectValue.Convert(argType))
+			methodArgs = append(methodArgs, reflectValue)
 		}
 		outs := methodValue.Call(methodArgs)
 		if len(outs) == 1 {
 			out := outs[0]
 			if out.Kind() != reflect.Interface {
-				return errors.Errorf("return value of setter method is not interface of error")
+				return newConfigError(setterPath, errors.Errorf("return value of setter method is not interface of error"))
 			}
 			outType := out.Type()
 			errorInterface := reflect.TypeOf((*error)(nil)).Elem()
-			if outType.Implements(errorInterface) {
-				return out.Interface().(error)
+			if !outType.Implements(errorInterface) {
+				return newConfigError(setterPath, errors.Errorf("return value of setter method is not interface of error"))
+			}
+			if !out.IsNil() {
+				return newConfigError(setterPath, out.Interface().(error))
 			}
-			return errors.Errorf("return value of setter method is not interface of error")
 		}
 	}
 	return nil
 }
+
+// newComponent is create filter, formatter or handler that is assignable to paramType
+func newComponent(structName string, paramType reflect.Type) (instance interface{}, err error) {
+	if paramType.Kind() != reflect.Interface {
+		return nil, errors.Errorf("unsupported type of nested component (%v)", paramType)
+	}
+	if filter, err := getFilter(structName); err == nil && reflect.TypeOf(filter).Implements(paramType) {
+		return filter, nil
+	}
+	if formatter, err := getFormatter(structName); err == nil && reflect.TypeOf(formatter).Implements(paramType) {
+		return formatter, nil
+	}
+	if handler, err := getHandler(structName); err == nil && reflect.TypeOf(handler).Implements(paramType) {
+		return handler, nil
+	}
+	return nil, errors.Errorf("not found component (%v) for %v", structName, paramType)
+}
+
+// setupComponent is create filter, formatter or handler of nested component definition for parameter of paramType
+func setupComponent(configStruct *configStruct, paramType reflect.Type, path string) (value reflect.Value, err error) {
+	instance, err := newComponent(expandEnv(configStruct.StructName), paramType)
+	if err != nil {
+		return reflect.Value{}, newConfigError(path+".structName", err)
+	}
+	if err := setupInstance(instance, configStruct, path); err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(instance), nil
+}
+
+// expandEnv is replace ${VAR} and ${VAR:-default} with value of environment variable.
+// default is used when VAR is unset or empty, and it can contain ${VAR}. $${ is replaced with literal ${.
+func expandEnv(s string) (expanded string) {
+	var builder strings.Builder
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "$${") {
+			builder.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			builder.WriteByte(s[i])
+			i++
+			continue
+		}
+		end := closingBrace(s, i+2)
+		if end < 0 {
+			builder.WriteString(s[i:])
+			break
+		}
+		name := s[i+2 : end]
+		defaultValue := ""
+		if idx := strings.Index(name, ":-"); idx >= 0 {
+			defaultValue = name[idx+2:]
+			name = name[:idx]
+		}
+		value := os.Getenv(name)
+		if value == "" {
+			value = expandEnv(defaultValue)
+		}
+		builder.WriteString(value)
+		i = end + 1
+	}
+	return builder.String()
+}
+
+// closingBrace is find index of "}" that closes "${" before start. It returns -1 if not found.
+func closingBrace(s string, start int) (index int) {
+	depth := 0
+	for i := start; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "$${"):
+			i += 3
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i += 2
+		case s[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+			i++
+		default:
+			i++
+		}
+	}
+	return -1
+}
@@ -0,0 +1,301 @@
+package belog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var (
+	componentTypes      = make(map[reflect.Type]string)
+	componentTypesMutex = new(sync.RWMutex)
+)
+
+//ConfigSetter is setter of component for exporting config.
+//SetterParams are string or nested Filter, Formatter or Handler.
+type ConfigSetter struct {
+	SetterName   string
+	SetterParams []interface{}
+}
+
+//ConfigExporter is interface of component that can export current settings as setters of config
+type ConfigExporter interface {
+	ExportSetters() (setters []ConfigSetter)
+}
+
+func logLevelName(logLevel LogLevel) (name string) {
+	name, ok := logLevelMap[logLevel]
+	if !ok {
+		return strconv.Itoa(int(logLevel))
+	}
+	return name
+}
+
+// recordComponentType is record registered name of component type to find it on exporting config.
+// builtin components are recorded on init and others are recorded when they are built from config,
+// so that constructors are not called only for finding names.
+func recordComponentType(name string, component interface{}) {
+	componentType := reflect.TypeOf(component)
+	componentTypesMutex.Lock()
+	defer componentTypesMutex.Unlock()
+	if recorded, ok := componentTypes[componentType]; ok && recorded <= name {
+		return
+	}
+	componentTypes[componentType] = name
+}
+
+// componentStructName is find registered name of component
+func componentStructName(component interface{}) (structName string, err error) {
+	componentType := reflect.TypeOf(component)
+	componentTypesMutex.RLock()
+	defer componentTypesMutex.RUnlock()
+	structName, ok := componentTypes[componentType]
+	if !ok {
+		return "", errors.Errorf("not registered component (%v)", componentType)
+	}
+	return structName, nil
+}
+
+// exportComponent is export component to config.
+// It returns nil and adds error with path if component or its nested component is not registered.
+func exportComponent(component interface{}, path string, configErrors *ConfigErrors) (config *configStruct) {
+	structName, err := componentStructName(component)
+	if err != nil {
+		*configErrors = append(*configErrors, newConfigError(path, err))
+		return nil
+	}
+	config = &configStruct{
+		StructName:    structName,
+		StructSetters: make([]*configStructSetter, 0),
+	}
+	exporter, ok := component.(ConfigExporter)
+	if !ok {
+		// settings of component are unknown
+		return config
+	}
+	for j, setter := range exporter.ExportSetters() {
+		structSetter := &configStructSetter{
+			SetterName:   setter.SetterName,
+			SetterParams: make([]*configSetterParam, 0, len(setter.SetterParams)),
+		}
+		for i, param := range setter.SetterParams {
+			switch p := param.(type) {
+			case string:
+				structSetter.SetterParams = append(structSetter.SetterParams, &configSetterParam{Value: p})
+			case Filter, Formatter, Handler:
+				nested := exportComponent(p, fmt.Sprintf("%v.structSetters[%d].setterParams[%d]", path, j, i), configErrors)
+				if nested == nil {
+					return nil
+				}
+				structSetter.SetterParams = append(structSetter.SetterParams, &configSetterParam{Component: nested})
+			default:
+				structSetter.SetterParams = append(structSetter.SetterParams, &configSetterParam{Value: fmt.Sprint(p)})
+			}
+		}
+		config.StructSetters = append(config.StructSetters, structSetter)
+	}
+	return config
+}
+
+// componentExporter is export components, components used by multiple loggers are exported as shared components
+type componentExporter struct {
+	counts map[interface{}]int
+	ids    map[interface{}]string
+	shared map[string]*configStruct
+	prefix string
+}
+
+func newComponentExporter(prefix string) (exporter *componentExporter) {
+	return &componentExporter{
+		counts: make(map[interface{}]int),
+		ids:    make(map[interface{}]string),
+		shared: make(map[string]*configStruct),
+		prefix: prefix,
+	}
+}
+
+func (e *componentExporter) count(component interface{}) {
+	if reflect.TypeOf(component).Comparable() {
+		e.counts[component]++
+	}
+}
+
+func (e *componentExporter) export(component interface{}, path string, configErrors *ConfigErrors) (config *configStruct) {
+	if !reflect.TypeOf(component).Comparable() || e.counts[component] < 2 {
+		return exportComponent(component, path, configErrors)
+	}
+	id, ok := e.ids[component]
+	if !ok {
+		config := exportComponent(component, path, configErrors)
+		if config == nil {
+			return nil
+		}
+		id = fmt.Sprintf("%v%d", e.prefix, len(e.ids)+1)
+		e.ids[component] = id
+		e.shared[id] = config
+	}
+	return &configStruct{Ref: id}
+}
+
+type loggerSnapshot struct {
+	filter              Filter
+	formatter           Formatter
+	handlers            []Handler
+	stackTraceLogLevel  LogLevel
+	asyncQueueSize      int
+	asyncOverflowPolicy OverflowPolicy
+}
+
+func (l *logger) snapshot() (snapshot *loggerSnapshot) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	snapshot = &loggerSnapshot{
+		filter:             l.filter,
+		formatter:          l.formatter,
+		handlers:           append([]Handler{}, l.handlers...),
+		stackTraceLogLevel: l.stackTraceLogLevel,
+	}
+	if l.queue != nil {
+		snapshot.asyncQueueSize = l.queue.queueSize
+		snapshot.asyncOverflowPolicy = l.queue.overflowPolicy
+	}
+	return snapshot
+}
+
+//ExportConfig is snapshot current settings of all loggers including default logger into ConfigLoggers.
+//Components used by multiple loggers are exported as shared components.
+//Components that are not registered are omitted, and ConfigErrors with their paths is returned with the rest of config.
+func ExportConfig() (configLoggers *ConfigLoggers, err error) {
+	snapshots := make(map[string]*loggerSnapshot)
+	snapshots["default"] = defaultLogger.snapshot()
+	loggersMutex.RLock()
+	for name, logger := range loggers {
+		snapshots[name] = logger.snapshot()
+	}
+	loggersMutex.RUnlock()
+	filterExporter := newComponentExporter("filter")
+	formatterExporter := newComponentExporter("formatter")
+	handlerExporter := newComponentExporter("handler")
+	names := make([]string, 0, len(snapshots))
+	for name, snapshot := range snapshots {
+		names = append(names, name)
+		filterExporter.count(snapshot.filter)
+		formatterExporter.count(snapshot.formatter)
+		for _, handler := range snapshot.handlers {
+			handlerExporter.count(handler)
+		}
+	}
+	sort.Strings(names)
+	configLoggers = &ConfigLoggers{
+		Loggers: make(map[string]configLogger),
+	}
+	var configErrors ConfigErrors
+	for _, name := range names {
+		snapshot := snapshots[name]
+		path := "loggers." + name
+		loggerConfig := configLogger{
+			Filter:    filterExporter.export(snapshot.filter, path+".filter", &configErrors),
+			Formatter: formatterExporter.export(snapshot.formatter, path+".formatter", &configErrors),
+		}
+		for i, handler := range snapshot.handlers {
+			handlerConfig := handlerExporter.export(handler, fmt.Sprintf("%v.handlers[%d]", path, i), &configErrors)
+			if handlerConfig == nil {
+				continue
+			}
+			loggerConfig.Handlers = append(loggerConfig.Handlers, handlerConfig)
+		}
+		if snapshot.stackTraceLogLevel > 0 {
+			loggerConfig.StackTraceLogLevel = logLevelName(snapshot.stackTraceLogLevel)
+		}
+		if snapshot.asyncQueueSize > 0 {
+			loggerConfig.Async = &configAsync{
+				QueueSize:      snapshot.asyncQueueSize,
+				OverflowPolicy: snapshot.asyncOverflowPolicy.String(),
+			}
+		}
+		configLoggers.Loggers[name] = loggerConfig
+	}
+	if len(filterExporter.shared) > 0 {
+		configLoggers.Filters = filterExporter.shared
+	}
+	if len(formatterExporter.shared) > 0 {
+		configLoggers.Formatters = formatterExporter.shared
+	}
+	if len(handlerExporter.shared) > 0 {
+		configLoggers.Handlers = handlerExporter.shared
+	}
+	if len(configErrors) > 0 {
+		return configLoggers, configErrors
+	}
+	return configLoggers, nil
+}
+
+//EncodeConfig is encode ConfigLoggers to format ("toml", "yaml" or "json")
+func EncodeConfig(configLoggers *ConfigLoggers, format string) (data []byte, err error) {
+	format, err = configFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "toml":
+		var buffer bytes.Buffer
+		if err := toml.NewEncoder(&buffer).Encode(configLoggers); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	case "yaml":
+		return yaml.Marshal(configLoggers)
+	default:
+		return json.MarshalIndent(configLoggers, "", "\t")
+	}
+}
+
+// writeTOMLSetterParam is write setter parameter as toml value. nested component is written as inline table.
+func writeTOMLSetterParam(buffer *bytes.Buffer, param *configSetterParam) (err error) {
+	if param.Component == nil {
+		// json string is valid basic string of toml
+		quoted, err := json.Marshal(param.Value)
+		if err != nil {
+			return err
+		}
+		buffer.Write(quoted)
+		return nil
+	}
+	structName, err := json.Marshal(param.Component.StructName)
+	if err != nil {
+		return err
+	}
+	buffer.WriteString("{ structName = ")
+	buffer.Write(structName)
+	buffer.WriteString(", structSetters = [")
+	for i, structSetter := range param.Component.StructSetters {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+		setterName, err := json.Marshal(structSetter.SetterName)
+		if err != nil {
+			return err
+		}
+		buffer.WriteString(" { setterName = ")
+		buffer.Write(setterName)
+		buffer.WriteString(", setterParams = [")
+		for j, setterParam := range structSetter.SetterParams {
+			if j > 0 {
+				buffer.WriteString(", ")
+			}
+			if err := writeTOMLSetterParam(buffer, setterParam); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("] }")
+	}
+	buffer.WriteString(" ] }")
+	return nil
+}
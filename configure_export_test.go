@@ -0,0 +1,149 @@
+package belog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportConfig(t *testing.T) {
+	// remove loggers of other tests
+	if err := ReplaceLoggers(&ConfigLoggers{}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	configLoggers, err := decodeConfigFile("./test/sample1.json")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	configLoggers.Handlers = map[string]*configStruct{
+		"file": {
+			StructName: "RotationFileHandler",
+			StructSetters: []*configStructSetter{
+				{SetterName: "SetLogFileName", SetterParams: newConfigSetterParams("belog-export.log")},
+				{SetterName: "SetLogDirPath", SetterParams: newConfigSetterParams("/var/tmp/belog-test")},
+			},
+		},
+	}
+	test1 := configLoggers.Loggers["test1"]
+	test1.Handlers = append(test1.Handlers, &configStruct{Ref: "file"})
+	configLoggers.Loggers["test1"] = test1
+	test2 := configLoggers.Loggers["test2"]
+	test2.Handlers = append(test2.Handlers, &configStruct{Ref: "file"})
+	configLoggers.Loggers["test2"] = test2
+	if err := SetupLoggers(configLoggers); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	exported, err := ExportConfig()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(exported.Loggers) != 3 {
+		t.Errorf("logger count mismatch (%v)", len(exported.Loggers))
+	}
+	if len(exported.Handlers) != 1 || exported.Loggers["test1"].Handlers[3].Ref == "" {
+		t.Errorf("shared handler is not exported")
+	}
+	if exported.Loggers["test2"].StackTraceLogLevel != "CRIT" || exported.Loggers["test2"].Async.OverflowPolicy != "dropOldest" {
+		t.Errorf("logger options are not exported")
+	}
+	expected, err := EncodeConfig(exported, "json")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// round trip through config file of each format
+	dir, err := ioutil.TempDir("", "belog-export")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+	for _, format := range []string{"toml", "yaml", "json"} {
+		data, err := EncodeConfig(exported, format)
+		if err != nil {
+			t.Errorf("%+v", err)
+			continue
+		}
+		configFilePath := filepath.Join(dir, "export."+format)
+		if err := ioutil.WriteFile(configFilePath, data, 0644); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if err := LoadConfig(configFilePath); err != nil {
+			t.Errorf("%v: %+v\n%v", format, err, string(data))
+			continue
+		}
+		reexported, err := ExportConfig()
+		if err != nil {
+			t.Errorf("%+v", err)
+			continue
+		}
+		actual, err := EncodeConfig(reexported, "json")
+		if err != nil {
+			t.Errorf("%+v", err)
+			continue
+		}
+		if string(actual) != string(expected) {
+			t.Errorf("config mismatch (%v)\n%v\n%v", format, string(expected), string(actual))
+		}
+	}
+}
+
+func TestExportConfigNotRegistered(t *testing.T) {
+	if err := ReplaceLoggers(&ConfigLoggers{}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	// handler built from config is exported by registered name
+	configLoggers := &ConfigLoggers{
+		Loggers: map[string]configLogger{
+			"exportRegistered": {
+				Filter:    &configStruct{StructName: "LogLevelFilter"},
+				Formatter: &configStruct{StructName: "StandardFormatter"},
+				Handlers:  []*configStruct{{StructName: "validateTestHandler"}},
+			},
+		},
+	}
+	if err := SetupLoggers(configLoggers); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := SetLogger("exportNotRegistered", NewLogLevelFilter(), NewStandardFormatter(), []Handler{NewConsoleHandler(), newBlockingHandler()}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	exported, err := ExportConfig()
+	configErrors, ok := err.(ConfigErrors)
+	if !ok || len(configErrors) != 1 || configErrors[0].Path != "loggers.exportNotRegistered.handlers[1]" {
+		t.Fatalf("error mismatch (%v)", err)
+	}
+	if exported == nil {
+		t.Fatalf("config is not exported")
+	}
+	if handlers := exported.Loggers["exportNotRegistered"].Handlers; len(handlers) != 1 || handlers[0].StructName != "ConsoleHandler" {
+		t.Errorf("handlers mismatch (%v)", handlers)
+	}
+	if handlers := exported.Loggers["exportRegistered"].Handlers; len(handlers) != 1 || handlers[0].StructName != "validateTestHandler" {
+		t.Errorf("handlers mismatch (%v)", handlers)
+	}
+
+	// rest of config can be loaded
+	data, err := EncodeConfig(exported, "json")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := LoadConfigFrom(bytes.NewReader(data), "json"); err != nil {
+		t.Fatalf("%+v\n%v", err, string(data))
+	}
+	reexported, err := ExportConfig()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	actual, err := EncodeConfig(reexported, "json")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if string(actual) != string(data) {
+		t.Errorf("config mismatch\n%v\n%v", string(data), string(actual))
+	}
+	if err := ReplaceLoggers(&ConfigLoggers{}); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build go1.16
+
+package belog
+
+import (
+	"io/fs"
+	"os"
+	"path"
+)
+
+//LoadConfigFS is load configuration file of fsys (e.g. embed.FS).
+//overrideFilePaths are optional configuration files on disk, they are merged in order on top of it.
+//override file that does not exist is ignored.
+func LoadConfigFS(fsys fs.FS, configFilePath string, overrideFilePaths ...string) (err error) {
+	configLoggers, err := decodeConfigFS(fsys, configFilePath)
+	if err != nil {
+		return err
+	}
+	for _, overrideFilePath := range overrideFilePaths {
+		override, err := decodeConfigFile(overrideFilePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		configLoggers = MergeConfig(configLoggers, override)
+	}
+	return SetupLoggers(configLoggers)
+}
+
+func decodeConfigFS(fsys fs.FS, configFilePath string) (configLoggers *ConfigLoggers, err error) {
+	buf, err := fs.ReadFile(fsys, configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(buf, path.Ext(configFilePath))
+}
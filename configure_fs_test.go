@@ -0,0 +1,55 @@
+//go:build go1.16
+
+package belog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadConfigFS(t *testing.T) {
+	base, err := ioutil.ReadFile("./test/sample1.yaml")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	fsys := fstest.MapFS{
+		"config/belog.yaml": &fstest.MapFile{Data: base},
+	}
+	dir, err := ioutil.TempDir("", "belog-fs")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+	overrideFilePath := filepath.Join(dir, "override.json")
+	override := `{
+	"loggers": {
+		"test2": {
+			"filter": {"structName": "LogLevelFilter", "structSetters": [{"setterName": "SetLogLevel", "setterParams": ["DEBUG"]}]},
+			"formatter": {"structName": "JSONFormatter"},
+			"handlers": [{"structName": "ConsoleHandler"}]
+		}
+	}
+}`
+	if err := ioutil.WriteFile(overrideFilePath, []byte(override), 0644); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := LoadConfigFS(fsys, "config/belog.yaml", overrideFilePath, filepath.Join(dir, "notfound.json")); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if lookupLogger("test1") == nil {
+		t.Errorf("logger of embedded config is not loaded")
+	}
+	test2 := lookupLogger("test2")
+	if _, ok := test2.formatter.(*JSONFormatter); !ok {
+		t.Errorf("logger of override config is not loaded")
+	}
+	if test2.queue != nil {
+		t.Errorf("logger of embedded config is not replaced")
+	}
+	if err := LoadConfigFS(fsys, "config/notfound.yaml"); err == nil {
+		t.Errorf("no error")
+	}
+}
@@ -1,6 +1,8 @@
 package belog
 
 import (
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -68,3 +70,376 @@ func TestGetLogger(t *testing.T) {
 		t.Errorf("l2 != ll2")
 	}
 }
+
+func TestGetLoggerHierarchy(t *testing.T) {
+	if err := LoadConfig("./test/sample1.jsn"); err != nil {
+		t.Errorf("%+v", err)
+	}
+	l1, ok := loggers["test1"]
+	if !ok {
+		t.Errorf("not found test1 logger")
+	}
+	loggerGroup := GetLoggerGroup("test1.pool.conn", "test1x", "unknown.pool")
+	if loggerGroup.loggers["test1.pool.conn"] != l1 {
+		t.Errorf("test1.pool.conn is not resolved to test1")
+	}
+	if loggerGroup.loggers["test1x"] != defaultLogger {
+		t.Errorf("test1x is not resolved to default")
+	}
+	if loggerGroup.loggers["unknown.pool"] != defaultLogger {
+		t.Errorf("unknown.pool is not resolved to default")
+	}
+}
+
+func TestLoadConfigLoggerOptions(t *testing.T) {
+	for _, configFilePath := range []string{"./test/sample1.json", "./test/sample1.toml", "./test/sample1.yaml"} {
+		if err := LoadConfig(configFilePath); err != nil {
+			t.Errorf("%+v", err)
+		}
+		if loggers["test1"].stackTraceLogLevel != 0 {
+			t.Errorf("stack trace log level of test1 mismatch (%v)", configFilePath)
+		}
+		if loggers["test2"].stackTraceLogLevel != LogLevelCrit {
+			t.Errorf("stack trace log level of test2 mismatch (%v)", configFilePath)
+		}
+		if loggers["test1"].queue != nil {
+			t.Errorf("async of test1 mismatch (%v)", configFilePath)
+		}
+		if queue := loggers["test2"].queue; queue == nil || queue.queueSize != 1024 || queue.overflowPolicy != OverflowPolicyDropOldest {
+			t.Errorf("async of test2 mismatch (%v)", configFilePath)
+		}
+		// nested chain filter
+		if !loggers["test2"].isEnabled("test2", LogLevelWarn) || loggers["test2"].isEnabled("test2", LogLevelNotice) {
+			t.Errorf("chain filter of test2 mismatch (%v)", configFilePath)
+		}
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	os.Setenv("BELOG_TEST_DIR", "/var/tmp/belog-test")
+	os.Setenv("BELOG_TEST_EMPTY", "")
+	os.Unsetenv("BELOG_TEST_UNSET")
+	testCases := map[string]string{
+		"${BELOG_TEST_DIR}":                             "/var/tmp/belog-test",
+		"dir=${BELOG_TEST_DIR}/log":                     "dir=/var/tmp/belog-test/log",
+		"${BELOG_TEST_UNSET}":                           "",
+		"${BELOG_TEST_UNSET:-7}":                        "7",
+		"${BELOG_TEST_EMPTY:-udp}":                      "udp",
+		"${BELOG_TEST_DIR:-/tmp}":                       "/var/tmp/belog-test",
+		"$BELOG_TEST_DIR ${BELOG_TEST_DIR":              "$BELOG_TEST_DIR ${BELOG_TEST_DIR",
+		"${BELOG_TEST_UNSET:-${BELOG_TEST_DIR}}/log":    "/var/tmp/belog-test/log",
+		"${BELOG_TEST_UNSET:-${BELOG_TEST_EMPTY:-udp}}": "udp",
+		"${BELOG_TEST_DIR:-${BELOG_TEST_UNSET}}":        "/var/tmp/belog-test",
+		"$${BELOG_TEST_DIR}":                            "${BELOG_TEST_DIR}",
+		"${BELOG_TEST_UNSET:-$${BELOG_TEST_DIR}":        "${BELOG_TEST_DIR",
+		"$${BELOG_TEST_DIR} ${BELOG_TEST_DIR}":          "${BELOG_TEST_DIR} /var/tmp/belog-test",
+	}
+	for s, expected := range testCases {
+		if expanded := expandEnv(s); expanded != expected {
+			t.Errorf("expanded mismatch (%v: %v != %v)", s, expanded, expected)
+		}
+	}
+}
+
+func TestSetupLoggersExpandEnv(t *testing.T) {
+	os.Setenv("BELOG_TEST_LOG_LEVEL", "5")
+	os.Unsetenv("BELOG_TEST_HANDLER")
+	configLoggers := &ConfigLoggers{
+		Loggers: map[string]configLogger{
+			"expandEnv": {
+				Filter: &configStruct{
+					StructName: "LogLevelFilter",
+					StructSetters: []*configStructSetter{
+						{SetterName: "SetLogLevel", SetterParams: newConfigSetterParams("${BELOG_TEST_LOG_LEVEL:-8}")},
+					},
+				},
+				Formatter: &configStruct{StructName: "StandardFormatter"},
+				Handlers: []*configStruct{
+					{StructName: "${BELOG_TEST_HANDLER:-ConsoleHandler}"},
+				},
+			},
+		},
+	}
+	if err := SetupLoggers(configLoggers); err != nil {
+		t.Errorf("%+v", err)
+	}
+	logger := GetLogger("expandEnv")
+	if !logger.IsEnabled(LogLevelWarn) || logger.IsEnabled(LogLevelNotice) {
+		t.Errorf("log level is not expanded")
+	}
+}
+
+func newConfigSetterParams(values ...string) (setterParams []*configSetterParam) {
+	for _, value := range values {
+		setterParams = append(setterParams, &configSetterParam{Value: value})
+	}
+	return setterParams
+}
+
+func TestSetupLoggersSharedHandler(t *testing.T) {
+	newLoggerConfig := func(handlerRef string) (loggerConfig configLogger) {
+		return configLogger{
+			Filter:    &configStruct{Ref: "level"},
+			Formatter: &configStruct{StructName: "StandardFormatter"},
+			Handlers:  []*configStruct{{Ref: handlerRef}},
+		}
+	}
+	configLoggers := &ConfigLoggers{
+		Filters: map[string]*configStruct{
+			"level": {StructName: "LogLevelFilter"},
+		},
+		Handlers: map[string]*configStruct{
+			"file": {
+				StructName: "RotationFileHandler",
+				StructSetters: []*configStructSetter{
+					{SetterName: "SetLogFileName", SetterParams: newConfigSetterParams("belog-shared.log")},
+					{SetterName: "SetLogDirPath", SetterParams: newConfigSetterParams("/var/tmp/belog-test")},
+				},
+			},
+		},
+		Loggers: map[string]configLogger{
+			"sharedA": newLoggerConfig("file"),
+			"sharedB": newLoggerConfig("file"),
+		},
+	}
+	if err := SetupLoggers(configLoggers); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	handler := lookupLogger("sharedA").handlers[0]
+	if lookupLogger("sharedB").handlers[0] != handler {
+		t.Errorf("handler is not shared")
+	}
+	if lookupLogger("sharedA").filter != lookupLogger("sharedB").filter {
+		t.Errorf("filter is not shared")
+	}
+
+	// shared handler is closed when last logger releases it
+	if err := GetLogger("sharedA").ChangeHandlers([]Handler{NewConsoleHandler()}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if !handler.IsOpened() {
+		t.Errorf("shared handler is closed")
+	}
+	if err := GetLogger("sharedB").ChangeHandlers([]Handler{NewConsoleHandler()}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if handler.IsOpened() {
+		t.Errorf("shared handler is not closed")
+	}
+
+	configLoggers.Loggers["sharedB"] = newLoggerConfig("unknown")
+	if err := ValidateLoggers(configLoggers); err == nil {
+		t.Errorf("no error")
+	}
+}
+
+func newTestLoggerConfig(logFileName string) (loggerConfig configLogger) {
+	return configLogger{
+		Filter:    &configStruct{StructName: "LogLevelFilter"},
+		Formatter: &configStruct{StructName: "StandardFormatter"},
+		Handlers: []*configStruct{
+			{
+				StructName: "RotationFileHandler",
+				StructSetters: []*configStructSetter{
+					{SetterName: "SetLogFileName", SetterParams: newConfigSetterParams(logFileName)},
+					{SetterName: "SetLogDirPath", SetterParams: newConfigSetterParams("/var/tmp/belog-test")},
+				},
+			},
+		},
+	}
+}
+
+func TestApplyLoggersAtomic(t *testing.T) {
+	handler := NewRotationFileHandler()
+	loggerSettings := map[string]*loggerSetting{
+		"atomicA": {
+			filter:    NewLogLevelFilter(),
+			formatter: NewStandardFormatter(),
+			handlers:  []Handler{handler},
+		},
+		"atomicB": {
+			filter:              NewLogLevelFilter(),
+			formatter:           NewStandardFormatter(),
+			handlers:            []Handler{NewConsoleHandler()},
+			asyncQueueSize:      10,
+			asyncOverflowPolicy: 0,
+		},
+	}
+	if err := applyLoggers(loggerSettings, false); err == nil {
+		t.Errorf("no error")
+	}
+	if lookupLogger("atomicA") != nil || lookupLogger("atomicB") != nil {
+		t.Errorf("config is partially applied")
+	}
+	if handler.IsOpened() {
+		t.Errorf("handler of failed config is opened")
+	}
+}
+
+func TestApplyLoggersAsyncRemoved(t *testing.T) {
+	newLoggerSettings := func() (loggerSettings map[string]*loggerSetting) {
+		return map[string]*loggerSetting{
+			"applyAsync": {
+				filter:              NewLogLevelFilter(),
+				formatter:           NewStandardFormatter(),
+				handlers:            []Handler{NewConsoleHandler()},
+				asyncQueueSize:      10,
+				asyncOverflowPolicy: OverflowPolicyBlock,
+			},
+		}
+	}
+	// logger is removed concurrently while applying
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			RemoveLogger("applyAsync")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if err := applyLoggers(newLoggerSettings(), false); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	<-done
+	if err := applyLoggers(newLoggerSettings(), false); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if snapshot := lookupLogger("applyAsync").snapshot(); snapshot.asyncQueueSize != 10 {
+		t.Errorf("async is not applied (%v)", snapshot.asyncQueueSize)
+	}
+	if err := RemoveLogger("applyAsync"); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+func TestReplaceLoggers(t *testing.T) {
+	configLoggers := &ConfigLoggers{
+		Loggers: map[string]configLogger{
+			"replaceKeep":   newTestLoggerConfig("belog-keep.log"),
+			"replaceRemove": newTestLoggerConfig("belog-remove.log"),
+		},
+	}
+	if err := SetupLoggers(configLoggers); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	removedHandler := lookupLogger("replaceRemove").handlers[0]
+	delete(configLoggers.Loggers, "replaceRemove")
+	if err := ReplaceLoggers(configLoggers); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if lookupLogger("replaceKeep") == nil {
+		t.Errorf("not found replaceKeep logger")
+	}
+	if lookupLogger("replaceRemove") != nil {
+		t.Errorf("replaceRemove logger is not removed")
+	}
+	if removedHandler.IsOpened() {
+		t.Errorf("handler of removed logger is not closed")
+	}
+}
+
+func TestRemoveLogger(t *testing.T) {
+	configLoggers := &ConfigLoggers{
+		Loggers: map[string]configLogger{
+			"remove": newTestLoggerConfig("belog-remove.log"),
+		},
+	}
+	if err := SetupLoggers(configLoggers); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	handler := lookupLogger("remove").handlers[0]
+	if err := RemoveLogger("remove"); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if lookupLogger("remove") != nil {
+		t.Errorf("logger is not removed")
+	}
+	if handler.IsOpened() {
+		t.Errorf("handler is not closed")
+	}
+	if err := RemoveLogger("remove"); err == nil {
+		t.Errorf("no error")
+	}
+	if err := RemoveLogger("default"); err == nil {
+		t.Errorf("no error")
+	}
+}
+
+func TestRemoveLoggerLoggerGroup(t *testing.T) {
+	formatter := NewStandardFormatter()
+	formatter.SetAppendNewLine(false)
+	formatter.SetLayout("%(loggerName) %(message)")
+	parentHandler := newBlockingHandler()
+	close(parentHandler.blocked)
+	if err := SetLogger("removeGroup", NewLogLevelFilter(), formatter, []Handler{parentHandler}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	childHandler := newBlockingHandler()
+	close(childHandler.blocked)
+	if err := SetLogger("removeGroup.child", NewLogLevelFilter(), formatter, []Handler{childHandler}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	loggerGroup := GetLoggerGroup("removeGroup.child")
+	loggerGroup.Info("test1")
+	if err := RemoveLogger("removeGroup.child"); err != nil {
+		t.Errorf("%+v", err)
+	}
+	loggerGroup.Info("test2")
+	if written := childHandler.getWritten(); len(written) != 1 || written[0] != "removeGroup.child test1" {
+		t.Errorf("written mismatch (%v)", written)
+	}
+	if written := parentHandler.getWritten(); len(written) != 1 || written[0] != "removeGroup.child test2" {
+		t.Errorf("written mismatch (%v)", written)
+	}
+	if err := RemoveLogger("removeGroup"); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+func TestLoadConfigFrom(t *testing.T) {
+	for format, configFilePath := range map[string]string{"toml": "./test/sample1.toml", "yml": "./test/sample1.yaml", "JSON": "./test/sample1.json"} {
+		file, err := os.Open(configFilePath)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		err = LoadConfigFrom(file, format)
+		file.Close()
+		if err != nil {
+			t.Errorf("%v: %+v", format, err)
+		}
+	}
+	if err := LoadConfigFrom(strings.NewReader("{}"), "ini"); err == nil {
+		t.Errorf("no error")
+	}
+}
+
+func TestMergeConfig(t *testing.T) {
+	base := &ConfigLoggers{
+		Handlers: map[string]*configStruct{"file": {StructName: "RotationFileHandler"}},
+		Loggers: map[string]configLogger{
+			"a": newTestLoggerConfig("a.log"),
+			"b": newTestLoggerConfig("b.log"),
+		},
+	}
+	override := &ConfigLoggers{
+		Handlers: map[string]*configStruct{"file": {StructName: "ConsoleHandler"}},
+		Loggers: map[string]configLogger{
+			"b": newTestLoggerConfig("b2.log"),
+			"c": newTestLoggerConfig("c.log"),
+		},
+	}
+	merged := MergeConfig(base, override)
+	if len(merged.Loggers) != 3 {
+		t.Errorf("logger count mismatch (%v)", len(merged.Loggers))
+	}
+	if merged.Loggers["b"].Handlers[0].StructSetters[0].SetterParams[0].Value != "b2.log" {
+		t.Errorf("logger is not overridden")
+	}
+	if merged.Handlers["file"].StructName != "ConsoleHandler" {
+		t.Errorf("shared handler is not overridden")
+	}
+	if len(base.Loggers) != 2 {
+		t.Errorf("base is modified")
+	}
+}
@@ -0,0 +1,205 @@
+package belog
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//ConfigError is error of config with path of problem (e.g. loggers.test1.handlers[2].structSetters[0])
+type ConfigError struct {
+	Path string
+	Err  error
+}
+
+//Error is return error message with path
+func (e *ConfigError) Error() (message string) {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Path, e.Err)
+}
+
+//Cause is return error without path
+func (e *ConfigError) Cause() (err error) {
+	return e.Err
+}
+
+//Unwrap is return error without path
+func (e *ConfigError) Unwrap() (err error) {
+	return e.Err
+}
+
+func newConfigError(path string, err error) (configError *ConfigError) {
+	if configError, ok := err.(*ConfigError); ok {
+		return configError
+	}
+	return &ConfigError{
+		Path: path,
+		Err:  err,
+	}
+}
+
+//ConfigErrors is all errors of config
+type ConfigErrors []*ConfigError
+
+//Error is return error messages joined with "; "
+func (e ConfigErrors) Error() (message string) {
+	messages := make([]string, 0, len(e))
+	for _, configError := range e {
+		messages = append(messages, configError.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+type configValidator struct {
+	configLoggers *ConfigLoggers
+	errors        ConfigErrors
+}
+
+func (v *configValidator) addError(path string, err error) {
+	v.errors = append(v.errors, newConfigError(path, err))
+}
+
+func sortedKeys(m interface{}) (keys []string) {
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (v *configValidator) validateStruct(configStruct *configStruct, path string, kind string, shared map[string]*configStruct) {
+	if configStruct == nil {
+		v.addError(path, errors.Errorf("no %v", kind))
+		return
+	}
+	if configStruct.Ref != "" {
+		if configStruct.StructName != "" || len(configStruct.StructSetters) > 0 {
+			v.addError(path, errors.Errorf("ref can not be used with structName and structSetters"))
+		}
+		if shared == nil {
+			v.addError(path+".ref", errors.Errorf("ref can not be used in shared %v", kind))
+		} else if _, ok := shared[configStruct.Ref]; !ok {
+			v.addError(path+".ref", errors.Errorf("not found shared %v (%v)", kind, configStruct.Ref))
+		}
+		return
+	}
+	structName := expandEnv(configStruct.StructName)
+	var instance interface{}
+	var err error
+	switch kind {
+	case "filter":
+		instance, err = getFilter(structName)
+	case "formatter":
+		instance, err = getFormatter(structName)
+	case "handler":
+		instance, err = getHandler(structName)
+	}
+	if err != nil {
+		v.addError(path+".structName", errors.Errorf("not found %v (%v)", kind, configStruct.StructName))
+		return
+	}
+	v.validateSetters(instance, configStruct, path)
+}
+
+func (v *configValidator) validateSetters(instance interface{}, configStruct *configStruct, path string) {
+	instanceValue := reflect.ValueOf(instance)
+	errorInterface := reflect.TypeOf((*error)(nil)).Elem()
+	for j, structSetter := range configStruct.StructSetters {
+		setterPath := fmt.Sprintf("%v.structSetters[%d]", path, j)
+		if structSetter == nil {
+			v.addError(setterPath, errors.Errorf("empty setter"))
+			continue
+		}
+		methodValue := instanceValue.MethodByName(strings.TrimSpace(structSetter.SetterName))
+		if !methodValue.IsValid() {
+			v.addError(setterPath, errors.Errorf("unexpected Method (%v)", structSetter.SetterName))
+			continue
+		}
+		methodType := methodValue.Type()
+		if len(structSetter.SetterParams) != methodType.NumIn() {
+			v.addError(setterPath, errors.Errorf("parameter count mismatch of setter method (%v: exp %v != act %v)", structSetter.SetterName, methodType.NumIn(), len(structSetter.SetterParams)))
+			continue
+		}
+		if methodType.NumOut() > 1 {
+			v.addError(setterPath, errors.Errorf("return value is too many of setter method"))
+		} else if methodType.NumOut() == 1 && methodType.Out(0) != errorInterface {
+			v.addError(setterPath, errors.Errorf("return value of setter method is not interface of error"))
+		}
+		for i, setterParam := range structSetter.SetterParams {
+			paramPath := fmt.Sprintf("%v.setterParams[%d]", setterPath, i)
+			if setterParam == nil {
+				v.addError(paramPath, errors.Errorf("empty setter parameter (%v)", structSetter.SetterName))
+				continue
+			}
+			if setterParam.Component != nil {
+				component, err := newComponent(expandEnv(setterParam.Component.StructName), methodType.In(i))
+				if err != nil {
+					v.addError(paramPath+".structName", err)
+					continue
+				}
+				v.validateSetters(component, setterParam.Component, paramPath)
+				continue
+			}
+			if _, err := parseSetterParam(expandEnv(setterParam.Value), methodType.In(i)); err != nil {
+				v.addError(paramPath, err)
+			}
+		}
+	}
+}
+
+func (v *configValidator) validateLogger(name string, loggerConfig configLogger) {
+	path := "loggers." + name
+	v.validateStruct(loggerConfig.Filter, path+".filter", "filter", v.configLoggers.Filters)
+	v.validateStruct(loggerConfig.Formatter, path+".formatter", "formatter", v.configLoggers.Formatters)
+	if len(loggerConfig.Handlers) == 0 {
+		v.addError(path+".handlers", errors.Errorf("no handlers"))
+	}
+	for i, handlerConfig := range loggerConfig.Handlers {
+		v.validateStruct(handlerConfig, fmt.Sprintf("%v.handlers[%d]", path, i), "handler", v.configLoggers.Handlers)
+	}
+	if loggerConfig.StackTraceLogLevel != "" {
+		if _, err := ParseLogLevel(loggerConfig.StackTraceLogLevel); err != nil {
+			v.addError(path+".stackTraceLogLevel", err)
+		}
+	}
+	if loggerConfig.Async != nil {
+		if loggerConfig.Async.QueueSize < 0 {
+			v.addError(path+".async.queueSize", errors.Errorf("invalid queue size (%v)", loggerConfig.Async.QueueSize))
+		}
+		if loggerConfig.Async.OverflowPolicy != "" {
+			if _, err := ParseOverflowPolicy(loggerConfig.Async.OverflowPolicy); err != nil {
+				v.addError(path+".async.overflowPolicy", err)
+			}
+		}
+	}
+}
+
+// validateConfig is validate all of config without calling setters and Open of components.
+func validateConfig(configLoggers *ConfigLoggers) (err error) {
+	if configLoggers == nil {
+		return ConfigErrors{newConfigError("", errors.Errorf("empty config"))}
+	}
+	v := &configValidator{
+		configLoggers: configLoggers,
+	}
+	for _, id := range sortedKeys(configLoggers.Filters) {
+		v.validateStruct(configLoggers.Filters[id], "filters."+id, "filter", nil)
+	}
+	for _, id := range sortedKeys(configLoggers.Formatters) {
+		v.validateStruct(configLoggers.Formatters[id], "formatters."+id, "formatter", nil)
+	}
+	for _, id := range sortedKeys(configLoggers.Handlers) {
+		v.validateStruct(configLoggers.Handlers[id], "handlers."+id, "handler", nil)
+	}
+	for _, name := range sortedKeys(configLoggers.Loggers) {
+		v.validateLogger(name, configLoggers.Loggers[name])
+	}
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package belog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var validateTestSetterCalled int
+
+type validateTestHandler struct {
+	ConsoleHandler
+}
+
+func (h *validateTestHandler) SetValue(value int) {
+	validateTestSetterCalled++
+}
+
+func init() {
+	RegisterHandler("validateTestHandler", func() (handler Handler) {
+		return &validateTestHandler{ConsoleHandler: *NewConsoleHandler()}
+	})
+}
+
+func TestValidateLoggers(t *testing.T) {
+	configLoggers := &ConfigLoggers{
+		Loggers: map[string]configLogger{
+			"test1": {
+				Filter:             &configStruct{StructName: "UnknownFilter"},
+				Formatter:          &configStruct{StructName: "StandardFormatter"},
+				StackTraceLogLevel: "LOUD",
+				Handlers: []*configStruct{
+					{StructName: "validateTestHandler", StructSetters: []*configStructSetter{
+						{SetterName: "SetValue", SetterParams: newConfigSetterParams("1")},
+					}},
+					{Ref: "unknown"},
+					{StructName: "ConsoleHandler", StructSetters: []*configStructSetter{
+						{SetterName: "SetOutputType", SetterParams: newConfigSetterParams("stdout")},
+						{SetterName: "SetColor", SetterParams: newConfigSetterParams("red")},
+						{SetterName: "SetConsoleColor", SetterParams: newConfigSetterParams("INFO", "purple")},
+					}},
+				},
+			},
+		},
+	}
+	err := ValidateLoggers(configLoggers)
+	configErrors, ok := err.(ConfigErrors)
+	if !ok {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	expectedPaths := []string{
+		"loggers.test1.filter.structName",
+		"loggers.test1.handlers[1].ref",
+		"loggers.test1.handlers[2].structSetters[1]",
+		"loggers.test1.handlers[2].structSetters[2].setterParams[1]",
+		"loggers.test1.stackTraceLogLevel",
+	}
+	if len(configErrors) != len(expectedPaths) {
+		t.Fatalf("error count mismatch (%v)", configErrors)
+	}
+	for i, expectedPath := range expectedPaths {
+		if configErrors[i].Path != expectedPath {
+			t.Errorf("path mismatch (%v != %v)", configErrors[i].Path, expectedPath)
+		}
+	}
+	if validateTestSetterCalled != 0 {
+		t.Errorf("setter is called by validation")
+	}
+	if err := SetupLoggers(configLoggers); err == nil {
+		t.Errorf("no error")
+	}
+	if validateTestSetterCalled != 0 {
+		t.Errorf("setter is called by invalid config")
+	}
+}
+
+func TestLoadConfigJSONErrorLine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "belog-validate")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+	configFilePath := filepath.Join(dir, "invalid.json")
+	if err := ioutil.WriteFile(configFilePath, []byte("{\n\t\"loggers\": {\n\t\t\"test1\": [\n\t}\n}\n"), 0644); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	err = LoadConfig(configFilePath)
+	if err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("unexpected error (%v)", err)
+	}
+}
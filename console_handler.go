@@ -2,7 +2,10 @@ package belog
 
 import (
 	"fmt"
+	"github.com/pkg/errors"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -47,6 +50,29 @@ const (
 )
 
 var (
+	consoleColorNameMap = map[ConsoleColor]string{
+		ConsoleNoColor:           "noColor",
+		ConsoleColorBlack:        "black",
+		ConsoleColorRed:          "red",
+		ConsoleColorGreen:        "green",
+		ConsoleColorYellow:       "yellow",
+		ConsoleColorBlue:         "blue",
+		ConsoleColorMagenta:      "magenta",
+		ConsoleColorCyan:         "cyan",
+		ConsoleColorLightGray:    "lightGray",
+		ConsoleColorDarkGray:     "darkGray",
+		ConsoleColorLightRed:     "lightRed",
+		ConsoleColorLightGreen:   "lightGreen",
+		ConsoleColorLightYellow:  "lightYellow",
+		ConsoleColorLightBlue:    "lightBlue",
+		ConsoleColorLightMagenta: "lightMagenta",
+		ConsoleColorLightCyan:    "lightCyan",
+		ConsoleColorWhite:        "white",
+	}
+	consoleOutputTypeNameMap = map[ConsoleOutputType]string{
+		ConsoleOutputTypeStdout: "stdout",
+		ConsoleOutputTypeStderr: "stderr",
+	}
 	colorMap = map[LogLevel]ConsoleColor{
 		LogLevelEmerg:  ConsoleNoColor,
 		LogLevelAlert:  ConsoleNoColor,
@@ -70,9 +96,67 @@ const (
 	ConsoleOutputTypeStderr = 2
 )
 
+//ParseConsoleColor is parse name (e.g. "red", "lightBlue") or number of console color
+func ParseConsoleColor(consoleColorString string) (consoleColor ConsoleColor, err error) {
+	consoleColorString = strings.TrimSpace(consoleColorString)
+	for consoleColor, name := range consoleColorNameMap {
+		if strings.EqualFold(name, consoleColorString) {
+			return consoleColor, nil
+		}
+	}
+	num, err := strconv.Atoi(consoleColorString)
+	if err != nil {
+		return 0, errors.Errorf("unexpected console color (%v)", consoleColorString)
+	}
+	consoleColor = ConsoleColor(num)
+	if _, ok := consoleColorNameMap[consoleColor]; !ok {
+		return 0, errors.Errorf("unexpected console color (%v)", consoleColorString)
+	}
+	return consoleColor, nil
+}
+
+//String is return name of console color
+func (c ConsoleColor) String() (name string) {
+	name, ok := consoleColorNameMap[c]
+	if !ok {
+		return "unknown"
+	}
+	return name
+}
+
+//ParseConsoleOutputType is parse name ("stdout", "stderr") or number of console output type
+func ParseConsoleOutputType(consoleOutputTypeString string) (consoleOutputType ConsoleOutputType, err error) {
+	consoleOutputTypeString = strings.TrimSpace(consoleOutputTypeString)
+	for consoleOutputType, name := range consoleOutputTypeNameMap {
+		if strings.EqualFold(name, consoleOutputTypeString) {
+			return consoleOutputType, nil
+		}
+	}
+	num, err := strconv.Atoi(consoleOutputTypeString)
+	if err != nil {
+		return 0, errors.Errorf("unexpected console output type (%v)", consoleOutputTypeString)
+	}
+	consoleOutputType = ConsoleOutputType(num)
+	if _, ok := consoleOutputTypeNameMap[consoleOutputType]; !ok {
+		return 0, errors.Errorf("unexpected console output type (%v)", consoleOutputTypeString)
+	}
+	return consoleOutputType, nil
+}
+
+//String is return name of console output type
+func (o ConsoleOutputType) String() (name string) {
+	name, ok := consoleOutputTypeNameMap[o]
+	if !ok {
+		return "unknown"
+	}
+	return name
+}
+
 //ConsoleHandler is handler of console
 type ConsoleHandler struct {
 	outputType ConsoleOutputType
+	colors     map[LogLevel]ConsoleColor
+	counters   *handlerCounters
 	mutex      *sync.RWMutex
 }
 
@@ -91,42 +175,56 @@ func (h *ConsoleHandler) Write(loggerName string, logEvent LogEvent, formattedLo
 	defer h.mutex.RUnlock()
 	switch h.outputType {
 	case ConsoleOutputTypeStdout:
-		color, ok := colorMap[logEvent.LogLevelNum()]
+		color, ok := h.colors[logEvent.LogLevelNum()]
 		if !ok {
 			color = ConsoleColorBlue
 		}
 		_, err := os.Stdout.WriteString(fmt.Sprintf("\x1b[%dm", color))
 		if err != nil {
-			// statistics
+			h.writeError(loggerName, logEvent, err)
 		}
-		_, err = os.Stdout.WriteString(formattedLog)
+		wlen, err := os.Stdout.WriteString(formattedLog)
 		if err != nil {
-			// statistics
+			h.writeError(loggerName, logEvent, err)
+		} else {
+			h.counters.write(wlen)
 		}
 		_, err = os.Stdout.WriteString("\x1b[0m")
 		if err != nil {
-			// statistics
+			h.writeError(loggerName, logEvent, err)
 		}
 	case ConsoleOutputTypeStderr:
-		color, ok := colorMap[logEvent.LogLevelNum()]
+		color, ok := h.colors[logEvent.LogLevelNum()]
 		if !ok {
 			color = ConsoleColorBlue
 		}
 		_, err := os.Stderr.WriteString(fmt.Sprintf("\x1b[%dm", color))
 		if err != nil {
-			// statistics
+			h.writeError(loggerName, logEvent, err)
 		}
-		_, err = os.Stderr.WriteString(formattedLog)
+		wlen, err := os.Stderr.WriteString(formattedLog)
 		if err != nil {
-			// statistics
+			h.writeError(loggerName, logEvent, err)
+		} else {
+			h.counters.write(wlen)
 		}
 		_, err = os.Stderr.WriteString("\033[0m")
 		if err != nil {
-			// statistics
+			h.writeError(loggerName, logEvent, err)
 		}
 	}
 }
 
+func (h *ConsoleHandler) writeError(loggerName string, logEvent LogEvent, err error) {
+	h.counters.writeError()
+	ReportError(loggerName, logEvent, "ConsoleHandler", err)
+}
+
+//Statistics is get statistics of handler
+func (h *ConsoleHandler) Statistics() (statistics HandlerStatistics) {
+	return h.counters.snapshot("ConsoleHandler")
+}
+
 //Flush is nothing to do
 func (h *ConsoleHandler) Flush() {
 }
@@ -142,17 +240,40 @@ func (h *ConsoleHandler) SetOutputType(outputType ConsoleOutputType) {
 	h.outputType = outputType
 }
 
-//SetConsoleColor is set color by log level
+//SetConsoleColor is set color by log level of this handler
 func (h *ConsoleHandler) SetConsoleColor(loglevel LogLevel, color ConsoleColor) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
-	colorMap[loglevel] = color
+	h.colors[loglevel] = color
+}
+
+//ExportSetters is export current settings as setters of config
+func (h *ConsoleHandler) ExportSetters() (setters []ConfigSetter) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	setters = []ConfigSetter{
+		{SetterName: "SetOutputType", SetterParams: []interface{}{h.outputType.String()}},
+	}
+	for logLevel := LogLevelEmerg; logLevel <= LogLevelTrace; logLevel++ {
+		color, ok := h.colors[logLevel]
+		if !ok || color == colorMap[logLevel] {
+			continue
+		}
+		setters = append(setters, ConfigSetter{SetterName: "SetConsoleColor", SetterParams: []interface{}{logLevelName(logLevel), color.String()}})
+	}
+	return setters
 }
 
 //NewConsoleHandler is create ConsoleHandler
 func NewConsoleHandler() (consoleHandler *ConsoleHandler) {
+	colors := make(map[LogLevel]ConsoleColor, len(colorMap))
+	for logLevel, color := range colorMap {
+		colors[logLevel] = color
+	}
 	return &ConsoleHandler{
 		outputType: ConsoleOutputTypeStdout,
+		colors:     colors,
+		counters:   new(handlerCounters),
 		mutex:      new(sync.RWMutex),
 	}
 }
@@ -161,4 +282,5 @@ func init() {
 	RegisterHandler("ConsoleHandler", func() (handler Handler) {
 		return NewConsoleHandler()
 	})
+	recordComponentType("ConsoleHandler", (*ConsoleHandler)(nil))
 }
@@ -0,0 +1,57 @@
+package belog
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+var (
+	contextExtractors      map[string]func(ctx context.Context) (value interface{}, ok bool)
+	contextExtractorsMutex *sync.RWMutex
+)
+
+//RegisterContextExtractor is register extractor of context value.
+//extracted value is set to attribute of log event by name.
+func RegisterContextExtractor(name string, extractor func(ctx context.Context) (value interface{}, ok bool)) {
+	contextExtractorsMutex.Lock()
+	defer contextExtractorsMutex.Unlock()
+	contextExtractors[name] = extractor
+}
+
+//UnregisterContextExtractor is unregister extractor of context value
+func UnregisterContextExtractor(name string) {
+	contextExtractorsMutex.Lock()
+	defer contextExtractorsMutex.Unlock()
+	delete(contextExtractors, name)
+}
+
+func extractContext(ctx context.Context) (keysAndValues []interface{}) {
+	if ctx == nil {
+		return nil
+	}
+	contextExtractorsMutex.RLock()
+	defer contextExtractorsMutex.RUnlock()
+	if len(contextExtractors) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(contextExtractors))
+	for name := range contextExtractors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	keysAndValues = make([]interface{}, 0, len(names)*2)
+	for _, name := range names {
+		value, ok := contextExtractors[name](ctx)
+		if !ok {
+			continue
+		}
+		keysAndValues = append(keysAndValues, name, value)
+	}
+	return keysAndValues
+}
+
+func init() {
+	contextExtractors = make(map[string]func(ctx context.Context) (value interface{}, ok bool))
+	contextExtractorsMutex = new(sync.RWMutex)
+}
@@ -0,0 +1,101 @@
+package belog
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"runtime"
+)
+
+const (
+	errorMaxCauses = 32
+)
+
+type causer interface {
+	Cause() error
+}
+
+type unwrapper interface {
+	Unwrap() error
+}
+
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+type errorInfo struct {
+	Message    string
+	Causes     []string
+	StackTrace []string
+}
+
+// isNil is check that value is nil or nil pointer in interface
+func isNil(value interface{}) (nilValue bool) {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// newErrorInfo is unpack message, chain of causes and stack trace of the deepest stackTracer
+func newErrorInfo(err error) (info *errorInfo) {
+	if isNil(err) {
+		return nil
+	}
+	info = &errorInfo{
+		Message: err.Error(),
+	}
+	var stackTrace errors.StackTrace
+	for i := 0; err != nil && i < errorMaxCauses; i++ {
+		if tracer, ok := err.(stackTracer); ok {
+			stackTrace = tracer.StackTrace()
+		}
+		var cause error
+		switch e := err.(type) {
+		case causer:
+			cause = e.Cause()
+		case unwrapper:
+			cause = e.Unwrap()
+		}
+		if isNil(cause) || cause == err {
+			break
+		}
+		if cause.Error() != err.Error() {
+			info.Causes = append(info.Causes, cause.Error())
+		}
+		err = cause
+	}
+	for _, frame := range stackTrace {
+		pc := uintptr(frame) - 1
+		fn := runtime.FuncForPC(pc)
+		if fn == nil {
+			info.StackTrace = append(info.StackTrace, "unknown")
+			continue
+		}
+		file, line := fn.FileLine(pc)
+		info.StackTrace = append(info.StackTrace, fmt.Sprintf("%v %v:%v", fn.Name(), file, line))
+	}
+	return info
+}
+
+// formatErrorInfo is format error as indented block preceded by new line
+func formatErrorInfo(err error) (formattedError string) {
+	info := newErrorInfo(err)
+	if info == nil {
+		return ""
+	}
+	buffer := new(bytes.Buffer)
+	fmt.Fprintf(buffer, "\n    error: %v", info.Message)
+	for _, cause := range info.Causes {
+		fmt.Fprintf(buffer, "\n    cause: %v", cause)
+	}
+	if len(info.StackTrace) > 0 {
+		buffer.WriteString("\n    stack:")
+		for _, frame := range info.StackTrace {
+			fmt.Fprintf(buffer, "\n        %v", frame)
+		}
+	}
+	return buffer.String()
+}
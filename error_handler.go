@@ -0,0 +1,128 @@
+package belog
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultErrorHandlerInterval = time.Second
+)
+
+//ErrorHandler is callback of failure of formatter and handler.
+//component is name of failed component (e.g. "RotationFileHandler").
+//loggerName and logEvent are empty if the failure is not related to log event.
+//It must not output log with logger of the failed handler.
+type ErrorHandler func(loggerName string, logEvent LogEvent, component string, err error)
+
+var (
+	globalErrorHandler  ErrorHandler
+	errorHandlers       map[string]ErrorHandler
+	errorHandlersMutex  *sync.RWMutex
+	defaultErrorLimiter *errorLimiter
+)
+
+type errorLimiter struct {
+	interval   time.Duration
+	lastTimes  map[string]time.Time
+	suppressed map[string]int
+	mutex      *sync.Mutex
+}
+
+func (e *errorLimiter) allow(component string, now time.Time) (allowed bool, suppressed int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if now.Sub(e.lastTimes[component]) < e.interval {
+		e.suppressed[component]++
+		return false, 0
+	}
+	suppressed = e.suppressed[component]
+	e.lastTimes[component] = now
+	e.suppressed[component] = 0
+	return true, suppressed
+}
+
+func defaultErrorHandler(loggerName string, logEvent LogEvent, component string, err error) {
+	allowed, suppressed := defaultErrorLimiter.allow(component, time.Now())
+	if !allowed {
+		return
+	}
+	message := fmt.Sprintf("belog: %v error (logger %v): %v", component, loggerName, err)
+	if suppressed > 0 {
+		message = fmt.Sprintf("%v (%v errors suppressed)", message, suppressed)
+	}
+	os.Stderr.WriteString(message + "\n")
+}
+
+//SetErrorHandler is set global error handler. nil is default error handler that reports to stderr with rate limiting.
+func SetErrorHandler(errorHandler ErrorHandler) {
+	errorHandlersMutex.Lock()
+	defer errorHandlersMutex.Unlock()
+	globalErrorHandler = errorHandler
+}
+
+//SetLoggerErrorHandler is set error handler of logger name. It is used for descendant logger names too.
+//nil is remove error handler of logger name.
+func SetLoggerErrorHandler(name string, errorHandler ErrorHandler) {
+	errorHandlersMutex.Lock()
+	defer errorHandlersMutex.Unlock()
+	if errorHandler == nil {
+		delete(errorHandlers, name)
+		return
+	}
+	errorHandlers[name] = errorHandler
+}
+
+//ReportError is report failure to error handler of logger name.
+//custom formatter and handler can use it too.
+func ReportError(loggerName string, logEvent LogEvent, component string, err error) {
+	if err == nil {
+		return
+	}
+	findErrorHandler(loggerName)(loggerName, logEvent, component, err)
+}
+
+func findErrorHandler(name string) (errorHandler ErrorHandler) {
+	errorHandlersMutex.RLock()
+	defer errorHandlersMutex.RUnlock()
+	for name != "" {
+		if errorHandler, ok := errorHandlers[name]; ok {
+			return errorHandler
+		}
+		idx := strings.LastIndex(name, ".")
+		if idx < 0 {
+			break
+		}
+		name = name[:idx]
+	}
+	if globalErrorHandler != nil {
+		return globalErrorHandler
+	}
+	return defaultErrorHandler
+}
+
+func componentName(component interface{}) (name string) {
+	componentType := reflect.TypeOf(component)
+	if componentType == nil {
+		return "unknown"
+	}
+	if componentType.Kind() == reflect.Ptr {
+		componentType = componentType.Elem()
+	}
+	return componentType.Name()
+}
+
+func init() {
+	errorHandlers = make(map[string]ErrorHandler)
+	errorHandlersMutex = new(sync.RWMutex)
+	defaultErrorLimiter = &errorLimiter{
+		interval:   defaultErrorHandlerInterval,
+		lastTimes:  make(map[string]time.Time),
+		suppressed: make(map[string]int),
+		mutex:      new(sync.Mutex),
+	}
+}
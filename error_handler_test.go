@@ -0,0 +1,61 @@
+package belog
+
+import (
+	"github.com/pkg/errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type errorFormatter struct {
+}
+
+func (f *errorFormatter) Format(loggerName string, log LogEvent) (formattedLog string, err error) {
+	return "", errors.New("format error")
+}
+
+func TestErrorHandler(t *testing.T) {
+	filter := NewLogLevelFilter()
+	if err := SetLogger("errorhandler", filter, &errorFormatter{}, []Handler{NewConsoleHandler()}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	var globalReported, loggerReported []string
+	SetErrorHandler(func(loggerName string, logEvent LogEvent, component string, err error) {
+		globalReported = append(globalReported, loggerName+" "+component+" "+err.Error())
+	})
+	defer SetErrorHandler(nil)
+	GetLogger("errorhandler.child").Info("test")
+	GetLogger("errorhandler").SetErrorHandler(func(loggerName string, logEvent LogEvent, component string, err error) {
+		loggerReported = append(loggerReported, loggerName+" "+logEvent.Message()+" "+component+" "+err.Error())
+	})
+	defer SetLoggerErrorHandler("errorhandler", nil)
+	GetLogger("errorhandler.child").Info("test")
+	if len(globalReported) != 1 || globalReported[0] != "errorhandler.child errorFormatter format error" {
+		t.Errorf("global error handler mismatch (%v)", globalReported)
+	}
+	if len(loggerReported) != 1 || loggerReported[0] != "errorhandler.child test errorFormatter format error" {
+		t.Errorf("logger error handler mismatch (%v)", loggerReported)
+	}
+}
+
+func TestErrorLimiter(t *testing.T) {
+	limiter := &errorLimiter{
+		interval:   time.Second,
+		lastTimes:  make(map[string]time.Time),
+		suppressed: make(map[string]int),
+		mutex:      new(sync.Mutex),
+	}
+	now := time.Now()
+	if allowed, _ := limiter.allow("test", now); !allowed {
+		t.Errorf("first error is not allowed")
+	}
+	if allowed, _ := limiter.allow("test", now.Add(time.Millisecond)); allowed {
+		t.Errorf("second error is allowed")
+	}
+	if allowed, _ := limiter.allow("other", now.Add(time.Millisecond)); !allowed {
+		t.Errorf("error of other component is not allowed")
+	}
+	if allowed, suppressed := limiter.allow("test", now.Add(2*time.Second)); !allowed || suppressed != 1 {
+		t.Errorf("error after interval mismatch (%v %v)", allowed, suppressed)
+	}
+}
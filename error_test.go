@@ -0,0 +1,101 @@
+package belog
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"strings"
+	"testing"
+)
+
+func TestNewErrorInfo(t *testing.T) {
+	err := errors.Wrap(errors.Wrap(errors.New("root"), "middle"), "top")
+	info := newErrorInfo(err)
+	if info.Message != "top: middle: root" {
+		t.Errorf("message mismatch (%v)", info.Message)
+	}
+	if len(info.Causes) != 2 || info.Causes[0] != "middle: root" || info.Causes[1] != "root" {
+		t.Errorf("causes mismatch (%v)", info.Causes)
+	}
+	if len(info.StackTrace) == 0 || !strings.HasPrefix(info.StackTrace[0], "github.com/potix/belog.TestNewErrorInfo ") {
+		t.Errorf("stack trace mismatch (%v)", info.StackTrace)
+	}
+	if newErrorInfo(nil) != nil {
+		t.Errorf("error info of nil is not nil")
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	logInfo := &logInfo{
+		message: "test",
+		err:     errors.Wrap(errors.New("root"), "top"),
+	}
+	formatter := NewStandardFormatter()
+	formatter.SetLayout("%(message)%(error)")
+	formattedLog, err := formatter.Format("test", logInfo)
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	lines := strings.Split(formattedLog, "\n")
+	if len(lines) < 6 || lines[0] != "test" || lines[1] != "    error: top: root" || lines[2] != "    cause: root" || lines[3] != "    stack:" {
+		t.Errorf("mismatch log (%v)", formattedLog)
+	}
+	if !strings.HasPrefix(lines[4], "        github.com/potix/belog.TestFormatError ") {
+		t.Errorf("mismatch log (%v)", formattedLog)
+	}
+	jsonFormatter := NewJSONFormatter()
+	formattedLog, err = jsonFormatter.Format("test", logInfo)
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	jsonLogInfo := new(jsonLogInfo)
+	if err := json.Unmarshal([]byte(formattedLog), jsonLogInfo); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if jsonLogInfo.Error == nil || jsonLogInfo.Error.Message != "top: root" || len(jsonLogInfo.Error.Causes) != 1 {
+		t.Errorf("mismatch log (%v)", formattedLog)
+	}
+}
+
+type nilTestError struct {
+	message string
+}
+
+func (e *nilTestError) Error() string {
+	return e.message
+}
+
+type nilCauseTestError struct {
+	cause *nilTestError
+}
+
+func (e nilCauseTestError) Error() string {
+	return "nil cause"
+}
+
+func (e nilCauseTestError) Cause() error {
+	return e.cause
+}
+
+func TestFormatNilError(t *testing.T) {
+	var nilErr *nilTestError
+	for _, err := range []error{nilErr, nilCauseTestError{}} {
+		logInfo := &logInfo{
+			message: "test",
+			err:     err,
+		}
+		formatter := NewStandardFormatter()
+		formatter.SetLayout("%(message)%(error)")
+		if _, err := formatter.Format("test", logInfo); err != nil {
+			t.Errorf("%+v", err)
+		}
+		if _, err := NewJSONFormatter().Format("test", logInfo); err != nil {
+			t.Errorf("%+v", err)
+		}
+	}
+	if newErrorInfo(nilErr) != nil {
+		t.Errorf("error info of nil pointer is not nil")
+	}
+	if info := newErrorInfo(nilCauseTestError{}); info == nil || info.Message != "nil cause" || len(info.Causes) != 0 {
+		t.Errorf("error info mismatch (%v)", info)
+	}
+}
@@ -13,12 +13,20 @@ type Filter interface {
 	Evaluate(loggerName string, log LogEvent) bool
 }
 
+//LevelFilter is optional interface of filter.
+//filter implements it to reject log level before creating log event.
+type LevelFilter interface {
+	IsEnabled(loggerName string, logLevel LogLevel) (enabled bool)
+}
+
 func getFilter(name string) (filter Filter, err error) {
 	newFunc, ok := filters[name]
 	if !ok {
 		return nil, errors.Errorf("not found filter (%v)", name)
 	}
-	return newFunc(), nil
+	filter = newFunc()
+	recordComponentType(name, filter)
+	return filter, nil
 }
 
 //RegisterFilter is register filter
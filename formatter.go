@@ -18,7 +18,9 @@ func getFormatter(name string) (formatter Formatter, err error) {
 	if !ok {
 		return nil, errors.Errorf("not found formatter (%v)", name)
 	}
-	return newFunc(), nil
+	formatter = newFunc()
+	recordComponentType(name, formatter)
+	return formatter, nil
 }
 
 //RegisterFormatter is register formatter
@@ -2,10 +2,14 @@ package belog
 
 import (
 	"github.com/pkg/errors"
+	"reflect"
+	"sync"
 )
 
 var (
-	handlers map[string]func() Handler
+	handlers              map[string]func() Handler
+	handlerRefCounts      = make(map[Handler]int)
+	handlerRefCountsMutex = new(sync.Mutex)
 )
 
 //Handler is interface of handler
@@ -22,7 +26,9 @@ func getHandler(name string) (Handler Handler, err error) {
 	if !ok {
 		return nil, errors.Errorf("not found Handler (%v)", name)
 	}
-	return newFunc(), nil
+	Handler = newFunc()
+	recordComponentType(name, Handler)
+	return Handler, nil
 }
 
 //RegisterHandler is register handler
@@ -33,6 +39,38 @@ func RegisterHandler(name string, newFunc func() Handler) {
 	handlers[name] = newFunc
 }
 
+// acquireHandlers is increment reference count of handlers and open them.
+func acquireHandlers(handlers []Handler) {
+	handlerRefCountsMutex.Lock()
+	defer handlerRefCountsMutex.Unlock()
+	for _, handler := range handlers {
+		if reflect.TypeOf(handler).Comparable() {
+			handlerRefCounts[handler]++
+		}
+		if !handler.IsOpened() {
+			handler.Open()
+		}
+	}
+}
+
+// releaseHandlers is decrement reference count of handlers and close them when they are no longer used by any logger.
+func releaseHandlers(handlers []Handler) {
+	handlerRefCountsMutex.Lock()
+	defer handlerRefCountsMutex.Unlock()
+	for _, handler := range handlers {
+		if reflect.TypeOf(handler).Comparable() {
+			if handlerRefCounts[handler] > 1 {
+				handlerRefCounts[handler]--
+				continue
+			}
+			delete(handlerRefCounts, handler)
+		}
+		if handler.IsOpened() {
+			handler.Close()
+		}
+	}
+}
+
 func init() {
 	if handlers == nil {
 		handlers = make(map[string]func() Handler)
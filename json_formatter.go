@@ -2,6 +2,7 @@ package belog
 
 import (
 	"encoding/json"
+	"fmt"
 	"sync"
 )
 
@@ -16,6 +17,8 @@ type jsonLogInfo struct {
 	FileName   string
 	LineNum    int
 	Message    string
+	StackTrace string     `json:",omitempty"`
+	Error      *errorInfo `json:",omitempty"`
 	Attrs      map[string]interface{}
 }
 
@@ -40,11 +43,18 @@ func (f *JSONFormatter) Format(loggerName string, log LogEvent) (formattedLog st
 		FileName:   log.FileName(),
 		LineNum:    log.LineNum(),
 		Message:    log.Message(),
-		Attrs:      log.GetAttrs(),
+		StackTrace: log.StackTrace(),
+		Error:      newErrorInfo(log.Err()),
+		Attrs:      normalizeAttrs(log.GetAttrs(), false),
 	}
 	serialized, err := json.Marshal(jsonLogInfo)
 	if err != nil {
-		return "", err
+		// retry with stringified attributes
+		jsonLogInfo.Attrs = normalizeAttrs(log.GetAttrs(), true)
+		serialized, err = json.Marshal(jsonLogInfo)
+		if err != nil {
+			return "", err
+		}
 	}
 	return string(append(serialized, '\n')), nil
 }
@@ -56,6 +66,15 @@ func (f *JSONFormatter) SetDateTimeLayout(dateTimeLayout string) {
 	f.dateTimeLayout = dateTimeLayout
 }
 
+//ExportSetters is export current settings as setters of config
+func (f *JSONFormatter) ExportSetters() (setters []ConfigSetter) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return []ConfigSetter{
+		{SetterName: "SetDateTimeLayout", SetterParams: []interface{}{f.dateTimeLayout}},
+	}
+}
+
 //NewJSONFormatter is create JSONFormatter
 func NewJSONFormatter() (jsonFormatter *JSONFormatter) {
 	return &JSONFormatter{
@@ -64,8 +83,28 @@ func NewJSONFormatter() (jsonFormatter *JSONFormatter) {
 	}
 }
 
+// normalizeAttrs is normalize values by attrValue and leave them to encoding/json.
+// values that can not be encoded are stringified when stringify is true.
+func normalizeAttrs(attrs map[string]interface{}, stringify bool) (normalizedAttrs map[string]interface{}) {
+	if attrs == nil {
+		return nil
+	}
+	normalizedAttrs = make(map[string]interface{}, len(attrs))
+	for key, value := range attrs {
+		value = attrValue(value)
+		if stringify && value != nil {
+			if _, err := json.Marshal(value); err != nil {
+				value = fmt.Sprint(value)
+			}
+		}
+		normalizedAttrs[key] = value
+	}
+	return normalizedAttrs
+}
+
 func init() {
 	RegisterFormatter("JSONFormatter", func() (formatter Formatter) {
 		return NewJSONFormatter()
 	})
+	recordComponentType("JSONFormatter", (*JSONFormatter)(nil))
 }
@@ -0,0 +1,58 @@
+package belog
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestFormatAttrs(t *testing.T) {
+	logInfo := &logInfo{
+		message: "test",
+	}
+	attrTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	logInfo.SetAttr("time", attrTime)
+	logInfo.SetAttr("err", errors.New("attr error"))
+	logInfo.SetAttr("raw", json.RawMessage(`{"a":1}`))
+	logInfo.SetAttr("func", func() {})
+	logInfo.SetAttr("duration", 2*time.Second)
+	jsonFormatter := NewJSONFormatter()
+	formattedLog, err := jsonFormatter.Format("test", logInfo)
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	jsonLogInfo := new(jsonLogInfo)
+	if err := json.Unmarshal([]byte(formattedLog), jsonLogInfo); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if jsonLogInfo.Attrs["time"] != "2020-01-02T03:04:05Z" {
+		t.Errorf("mismatch time (%v)", formattedLog)
+	}
+	if jsonLogInfo.Attrs["err"] != "attr error" {
+		t.Errorf("mismatch err (%v)", formattedLog)
+	}
+	if raw, ok := jsonLogInfo.Attrs["raw"].(map[string]interface{}); !ok || raw["a"] != float64(1) {
+		t.Errorf("mismatch raw (%v)", formattedLog)
+	}
+	if _, ok := jsonLogInfo.Attrs["func"].(string); !ok {
+		t.Errorf("mismatch func (%v)", formattedLog)
+	}
+	if jsonLogInfo.Attrs["duration"] != "2s" {
+		t.Errorf("mismatch duration (%v)", formattedLog)
+	}
+
+	// standard formatter renders same values
+	delete(logInfo.attrs, "func")
+	formatter := NewStandardFormatter()
+	formatter.SetAppendNewLine(false)
+	formatter.SetLayout("%(message)%(attrs)")
+	formattedLog, err = formatter.Format("test", logInfo)
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp := `test duration=2s err="attr error" raw="{\"a\":1}" time=2020-01-02T03:04:05Z`
+	if formattedLog != exp {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, formattedLog)
+	}
+}
@@ -1,9 +1,20 @@
 package belog
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 )
 
+const (
+	attrBadKey = "!BADKEY"
+)
+
 var (
 	logLevelMap = map[LogLevel]string{
 		LogLevelEmerg:  "EMERG",
@@ -30,6 +41,8 @@ type LogEvent interface {
 	FileName() (fileName string)
 	LineNum() (lineNum int)
 	Message() (message string)
+	StackTrace() (stackTrace string)
+	Err() (err error)
 	SetAttr(key string, value interface{})
 	GetAttr(key string) (value interface{})
 	GetAttrs() map[string]interface{}
@@ -44,8 +57,10 @@ type logInfo struct {
 	pc       uintptr
 	fileName string
 	lineNum  int
-	message  string
-	attrs    map[string]interface{}
+	message    string
+	stackTrace string
+	err        error
+	attrs      map[string]interface{}
 }
 
 //Program is return program
@@ -102,6 +117,16 @@ func (l *logInfo) Message() (message string) {
 	return l.message
 }
 
+//StackTrace is return stack trace. it is empty if stack trace is not captured.
+func (l *logInfo) StackTrace() (stackTrace string) {
+	return l.stackTrace
+}
+
+//Err is return attached error. it is nil if error is not attached.
+func (l *logInfo) Err() (err error) {
+	return l.err
+}
+
 //SetAttr is set attribute
 func (l *logInfo) SetAttr(key string, value interface{}) {
 	if l.attrs == nil {
@@ -126,3 +151,109 @@ func (l *logInfo) GetAttr(key string) (value interface{}) {
 func (l *logInfo) GetAttrs() map[string]interface{} {
 	return l.attrs
 }
+
+func (l *logInfo) setKeysAndValues(keysAndValues []interface{}) {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			l.SetAttr(attrBadKey, keysAndValues[i])
+			i--
+			continue
+		}
+		if i+1 >= len(keysAndValues) {
+			l.SetAttr(key, nil)
+			break
+		}
+		l.SetAttr(key, keysAndValues[i+1])
+	}
+}
+
+// attrValue is normalize attribute value in the same way for all formatters.
+// error is converted to message, json.Marshaler is kept and fmt.Stringer is converted to string.
+func attrValue(value interface{}) (normalized interface{}) {
+	if isNil(value) {
+		return nil
+	}
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case json.Marshaler:
+		return value
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return value
+	}
+}
+
+// attrText is text of normalized attribute value. json.Marshaler is its json, and json string is unquoted.
+func attrText(value interface{}) (text string) {
+	value = attrValue(value)
+	marshaler, ok := value.(json.Marshaler)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	data, err := marshaler.MarshalJSON()
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	if err := json.Unmarshal(data, &text); err == nil {
+		return text
+	}
+	return string(data)
+}
+
+//ParseLogLevel is parse log level name (e.g. "DEBUG") or log level number (e.g. "8")
+func ParseLogLevel(logLevelString string) (logLevel LogLevel, err error) {
+	logLevelString = strings.ToUpper(strings.TrimSpace(logLevelString))
+	for logLevel, name := range logLevelMap {
+		if name == logLevelString {
+			return logLevel, nil
+		}
+	}
+	num, err := strconv.Atoi(logLevelString)
+	if err != nil {
+		return 0, errors.Errorf("unexpected log level (%v)", logLevelString)
+	}
+	logLevel = LogLevel(num)
+	if _, ok := logLevelMap[logLevel]; !ok {
+		return 0, errors.Errorf("unexpected log level (%v)", logLevelString)
+	}
+	return logLevel, nil
+}
+
+// captureStackTrace is capture stack trace from the caller identified by skip as runtime.Caller
+func captureStackTrace(skip int) (stackTrace string) {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	return stackTraceFromCallers(pcs[:n])
+}
+
+// captureStackTraceFromPC is capture stack trace from the caller identified by pc as runtime.Callers
+func captureStackTraceFromPC(pc uintptr) (stackTrace string) {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	for i, callerPc := range pcs[:n] {
+		if callerPc == pc {
+			return stackTraceFromCallers(pcs[i:n])
+		}
+	}
+	// pc is not found in current goroutine, so only frame of pc is available
+	return stackTraceFromCallers([]uintptr{pc})
+}
+
+func stackTraceFromCallers(pcs []uintptr) (stackTrace string) {
+	if len(pcs) == 0 {
+		return ""
+	}
+	frames := runtime.CallersFrames(pcs)
+	buffer := new(bytes.Buffer)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(buffer, "%v\n\t%v:%v\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return buffer.String()
+}
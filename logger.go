@@ -1,12 +1,15 @@
 package belog
 
 import (
+	"context"
 	"fmt"
 	"github.com/pkg/errors"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -35,12 +38,15 @@ const (
 )
 
 var (
-	program       string
-	pid           int
-	hostname      string
-	defaultLogger *logger
-	loggers       map[string]*logger
-	loggersMutex  *sync.RWMutex
+	exitFunc       = os.Exit
+	program        string
+	pid            int
+	hostname       string
+	defaultLogger  *logger
+	fallbackLogger *logger
+	loggers        map[string]*logger
+	loggersMutex   *sync.RWMutex
+	exitFuncMutex  *sync.RWMutex
 )
 
 //
@@ -49,10 +55,17 @@ var (
 
 //LoggerGroup is logger group
 type LoggerGroup struct {
-	loggers map[string]*logger
+	loggers       map[string]*logger
+	keysAndValues []interface{}
+	callerSkip    int
+	err           error
 }
 
-func (l *LoggerGroup) logBase(logLevel LogLevel, message string) {
+func (l *LoggerGroup) logBase(callerSkip int, ctx context.Context, logLevel LogLevel, message string, keysAndValues []interface{}) {
+	if !l.IsEnabled(logLevel) {
+		l.countFiltered()
+		return
+	}
 	logInfo := &logInfo{
 		program:  program,
 		pid:      pid,
@@ -61,60 +74,332 @@ func (l *LoggerGroup) logBase(logLevel LogLevel, message string) {
 		logLevel: logLevel,
 		message:  message,
 	}
-	pc, fileName, lineNum, ok := runtime.Caller(2)
+	pc, fileName, lineNum, ok := runtime.Caller(2 + callerSkip + l.callerSkip)
 	if ok {
 		logInfo.pc = pc
 		logInfo.fileName = fileName
 		logInfo.lineNum = lineNum
 	}
+	for _, logger := range l.loggers {
+		if logger.isStackTraceEnabled(logLevel) {
+			logInfo.stackTrace = captureStackTrace(2 + callerSkip + l.callerSkip)
+			break
+		}
+	}
+	logInfo.err = l.err
+	logInfo.setKeysAndValues(l.keysAndValues)
+	logInfo.setKeysAndValues(extractContext(ctx))
+	logInfo.setKeysAndValues(keysAndValues)
 	for name, logger := range l.loggers {
 		logger.log(name, logInfo)
 	}
 }
 
+func (l *LoggerGroup) countFiltered() {
+	for _, logger := range l.loggers {
+		logger.countFiltered()
+	}
+}
+
+//IsEnabled is check that log level is enabled by any logger of logger group
+func (l *LoggerGroup) IsEnabled(logLevel LogLevel) (enabled bool) {
+	for name, logger := range l.loggers {
+		if logger.isEnabled(name, logLevel) {
+			return true
+		}
+	}
+	return false
+}
+
 //Emerg is output log of emergency level with logger group
 func (l *LoggerGroup) Emerg(format string, args ...interface{}) {
-	l.logBase(LogLevelEmerg, fmt.Sprintf(format, args...))
+	l.logBase(0, nil, LogLevelEmerg, fmt.Sprintf(format, args...), nil)
 }
 
 //Alert is output log of alert level with logger group
 func (l *LoggerGroup) Alert(format string, args ...interface{}) {
-	l.logBase(LogLevelAlert, fmt.Sprintf(format, args...))
+	l.logBase(0, nil, LogLevelAlert, fmt.Sprintf(format, args...), nil)
 }
 
 //Crit is output log of critical level with logger group
 func (l *LoggerGroup) Crit(format string, args ...interface{}) {
-	l.logBase(LogLevelCrit, fmt.Sprintf(format, args...))
+	l.logBase(0, nil, LogLevelCrit, fmt.Sprintf(format, args...), nil)
 }
 
 //Error is output log of error level with logger group
 func (l *LoggerGroup) Error(format string, args ...interface{}) {
-	l.logBase(LogLevelError, fmt.Sprintf(format, args...))
+	l.logBase(0, nil, LogLevelError, fmt.Sprintf(format, args...), nil)
 }
 
 //Warn is output log of warn level with logger group
 func (l *LoggerGroup) Warn(format string, args ...interface{}) {
-	l.logBase(LogLevelWarn, fmt.Sprintf(format, args...))
+	l.logBase(0, nil, LogLevelWarn, fmt.Sprintf(format, args...), nil)
 }
 
 //Notice is output log of notice level with logger group
 func (l *LoggerGroup) Notice(format string, args ...interface{}) {
-	l.logBase(LogLevelNotice, fmt.Sprintf(format, args...))
+	l.logBase(0, nil, LogLevelNotice, fmt.Sprintf(format, args...), nil)
 }
 
 //Info is output log of info level with logger group
 func (l *LoggerGroup) Info(format string, args ...interface{}) {
-	l.logBase(LogLevelInfo, fmt.Sprintf(format, args...))
+	l.logBase(0, nil, LogLevelInfo, fmt.Sprintf(format, args...), nil)
 }
 
 //Debug is output log of debug level with logger group
 func (l *LoggerGroup) Debug(format string, args ...interface{}) {
-	l.logBase(LogLevelDebug, fmt.Sprintf(format, args...))
+	l.logBase(0, nil, LogLevelDebug, fmt.Sprintf(format, args...), nil)
 }
 
 //Trace is output log of trace level with logger group
 func (l *LoggerGroup) Trace(format string, args ...interface{}) {
-	l.logBase(LogLevelTrace, fmt.Sprintf(format, args...))
+	l.logBase(0, nil, LogLevelTrace, fmt.Sprintf(format, args...), nil)
+}
+
+//Emergw is output log of emergency level with key value pairs with logger group
+func (l *LoggerGroup) Emergw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelEmerg, message, keysAndValues)
+}
+
+//Alertw is output log of alert level with key value pairs with logger group
+func (l *LoggerGroup) Alertw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelAlert, message, keysAndValues)
+}
+
+//Critw is output log of critical level with key value pairs with logger group
+func (l *LoggerGroup) Critw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelCrit, message, keysAndValues)
+}
+
+//Errorw is output log of error level with key value pairs with logger group
+func (l *LoggerGroup) Errorw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelError, message, keysAndValues)
+}
+
+//Warnw is output log of warn level with key value pairs with logger group
+func (l *LoggerGroup) Warnw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelWarn, message, keysAndValues)
+}
+
+//Noticew is output log of notice level with key value pairs with logger group
+func (l *LoggerGroup) Noticew(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelNotice, message, keysAndValues)
+}
+
+//Infow is output log of info level with key value pairs with logger group
+func (l *LoggerGroup) Infow(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelInfo, message, keysAndValues)
+}
+
+//Debugw is output log of debug level with key value pairs with logger group
+func (l *LoggerGroup) Debugw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelDebug, message, keysAndValues)
+}
+
+//Tracew is output log of trace level with key value pairs with logger group
+func (l *LoggerGroup) Tracew(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelTrace, message, keysAndValues)
+}
+
+//EmergContext is output log of emergency level with values of context with logger group
+func (l *LoggerGroup) EmergContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelEmerg, fmt.Sprintf(format, args...), nil)
+}
+
+//AlertContext is output log of alert level with values of context with logger group
+func (l *LoggerGroup) AlertContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelAlert, fmt.Sprintf(format, args...), nil)
+}
+
+//CritContext is output log of critical level with values of context with logger group
+func (l *LoggerGroup) CritContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelCrit, fmt.Sprintf(format, args...), nil)
+}
+
+//ErrorContext is output log of error level with values of context with logger group
+func (l *LoggerGroup) ErrorContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelError, fmt.Sprintf(format, args...), nil)
+}
+
+//WarnContext is output log of warn level with values of context with logger group
+func (l *LoggerGroup) WarnContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelWarn, fmt.Sprintf(format, args...), nil)
+}
+
+//NoticeContext is output log of notice level with values of context with logger group
+func (l *LoggerGroup) NoticeContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelNotice, fmt.Sprintf(format, args...), nil)
+}
+
+//InfoContext is output log of info level with values of context with logger group
+func (l *LoggerGroup) InfoContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelInfo, fmt.Sprintf(format, args...), nil)
+}
+
+//DebugContext is output log of debug level with values of context with logger group
+func (l *LoggerGroup) DebugContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelDebug, fmt.Sprintf(format, args...), nil)
+}
+
+//TraceContext is output log of trace level with values of context with logger group
+func (l *LoggerGroup) TraceContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelTrace, fmt.Sprintf(format, args...), nil)
+}
+
+//EmergFunc is output log of emergency level with logger group. messageFunc is called only if the level is enabled.
+func (l *LoggerGroup) EmergFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelEmerg) {
+		l.countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelEmerg, messageFunc(), nil)
+}
+
+//AlertFunc is output log of alert level with logger group. messageFunc is called only if the level is enabled.
+func (l *LoggerGroup) AlertFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelAlert) {
+		l.countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelAlert, messageFunc(), nil)
+}
+
+//CritFunc is output log of critical level with logger group. messageFunc is called only if the level is enabled.
+func (l *LoggerGroup) CritFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelCrit) {
+		l.countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelCrit, messageFunc(), nil)
+}
+
+//ErrorFunc is output log of error level with logger group. messageFunc is called only if the level is enabled.
+func (l *LoggerGroup) ErrorFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelError) {
+		l.countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelError, messageFunc(), nil)
+}
+
+//WarnFunc is output log of warn level with logger group. messageFunc is called only if the level is enabled.
+func (l *LoggerGroup) WarnFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelWarn) {
+		l.countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelWarn, messageFunc(), nil)
+}
+
+//NoticeFunc is output log of notice level with logger group. messageFunc is called only if the level is enabled.
+func (l *LoggerGroup) NoticeFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelNotice) {
+		l.countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelNotice, messageFunc(), nil)
+}
+
+//InfoFunc is output log of info level with logger group. messageFunc is called only if the level is enabled.
+func (l *LoggerGroup) InfoFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelInfo) {
+		l.countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelInfo, messageFunc(), nil)
+}
+
+//DebugFunc is output log of debug level with logger group. messageFunc is called only if the level is enabled.
+func (l *LoggerGroup) DebugFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelDebug) {
+		l.countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelDebug, messageFunc(), nil)
+}
+
+//TraceFunc is output log of trace level with logger group. messageFunc is called only if the level is enabled.
+func (l *LoggerGroup) TraceFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelTrace) {
+		l.countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelTrace, messageFunc(), nil)
+}
+
+//With is create logger group with bound key value pairs.
+//bound key value pairs are set to every log event of created logger group.
+func (l *LoggerGroup) With(keysAndValues ...interface{}) (loggerGroup *LoggerGroup) {
+	boundKeysAndValues := make([]interface{}, 0, len(l.keysAndValues)+len(keysAndValues))
+	boundKeysAndValues = append(boundKeysAndValues, l.keysAndValues...)
+	boundKeysAndValues = append(boundKeysAndValues, keysAndValues...)
+	return &LoggerGroup{
+		loggers:       l.loggers,
+		keysAndValues: boundKeysAndValues,
+		callerSkip:    l.callerSkip,
+		err:           l.err,
+	}
+}
+
+//WithError is create logger group with attached error.
+//message, causes and stack trace of the error are output by formatter.
+func (l *LoggerGroup) WithError(err error) (loggerGroup *LoggerGroup) {
+	return &LoggerGroup{
+		loggers:       l.loggers,
+		keysAndValues: l.keysAndValues,
+		callerSkip:    l.callerSkip,
+		err:           err,
+	}
+}
+
+//WithCallerSkip is create logger group that skips additional stack frames to find caller.
+//It is useful for wrapper function of logger group.
+func (l *LoggerGroup) WithCallerSkip(callerSkip int) (loggerGroup *LoggerGroup) {
+	return &LoggerGroup{
+		loggers:       l.loggers,
+		keysAndValues: l.keysAndValues,
+		callerSkip:    l.callerSkip + callerSkip,
+		err:           l.err,
+	}
+}
+
+//Output is output log with logger group. callerSkip is count of additional stack frames to skip to find caller.
+func (l *LoggerGroup) Output(callerSkip int, logLevel LogLevel, message string) {
+	l.logBase(callerSkip, nil, logLevel, message, nil)
+}
+
+//Outputw is output log with key value pairs with logger group. callerSkip is count of additional stack frames to skip to find caller.
+func (l *LoggerGroup) Outputw(callerSkip int, logLevel LogLevel, message string, keysAndValues ...interface{}) {
+	l.logBase(callerSkip, nil, logLevel, message, keysAndValues)
+}
+
+//Fatal is output log of emergency level with logger group, flush all loggers and exit
+func (l *LoggerGroup) Fatal(format string, args ...interface{}) {
+	l.logBase(0, nil, LogLevelEmerg, fmt.Sprintf(format, args...), nil)
+	FlushAll()
+	getExitFunc()(1)
+}
+
+//Fatalw is output log of emergency level with key value pairs with logger group, flush all loggers and exit
+func (l *LoggerGroup) Fatalw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelEmerg, message, keysAndValues)
+	FlushAll()
+	getExitFunc()(1)
+}
+
+//Panic is output log of emergency level with logger group, flush all loggers and panic
+func (l *LoggerGroup) Panic(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	l.logBase(0, nil, LogLevelEmerg, message, nil)
+	FlushAll()
+	panic(message)
+}
+
+//Panicw is output log of emergency level with key value pairs with logger group, flush all loggers and panic
+func (l *LoggerGroup) Panicw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelEmerg, message, keysAndValues)
+	FlushAll()
+	panic(message)
 }
 
 //Flush is flush log with logger group
@@ -151,6 +436,24 @@ func (l *LoggerGroup) ChangeHandlersByLoggerName(name string, handlers []Handler
 	return logger.changeHandlers(handlers)
 }
 
+//ChangeStackTraceLogLevelByLoggerName is change log level to capture stack trace by logger name of logger group
+func (l *LoggerGroup) ChangeStackTraceLogLevelByLoggerName(name string, stackTraceLogLevel LogLevel) (error) {
+	logger, ok := l.loggers[name]
+	if !ok {
+		return errors.Errorf("not found name")
+	}
+	return logger.changeStackTraceLogLevel(stackTraceLogLevel)
+}
+
+//ChangeAsyncByLoggerName is change async mode by logger name of logger group
+func (l *LoggerGroup) ChangeAsyncByLoggerName(name string, queueSize int, overflowPolicy OverflowPolicy) (error) {
+	logger, ok := l.loggers[name]
+	if !ok {
+		return errors.Errorf("not found name")
+	}
+	return logger.changeAsync(queueSize, overflowPolicy)
+}
+
 //ChangeFilter is change fileter of logger group
 func (l *LoggerGroup) ChangeFilter(filter Filter) (err error) {
 	for _, logger := range l.loggers {
@@ -184,6 +487,28 @@ func (l *LoggerGroup) ChangeHandlers(handlers []Handler) (err error) {
 	return nil
 }
 
+//ChangeStackTraceLogLevel is change log level to capture stack trace of logger group
+func (l *LoggerGroup) ChangeStackTraceLogLevel(stackTraceLogLevel LogLevel) (err error) {
+	for _, logger := range l.loggers {
+		err = logger.changeStackTraceLogLevel(stackTraceLogLevel)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ChangeAsync is change async mode of logger group
+func (l *LoggerGroup) ChangeAsync(queueSize int, overflowPolicy OverflowPolicy) (err error) {
+	for _, logger := range l.loggers {
+		err = logger.changeAsync(queueSize, overflowPolicy)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 //GetLoggerGroup is get logger group
 func GetLoggerGroup(names ...string) (loggerGroup *LoggerGroup) {
 	loggersMutex.RLock()
@@ -192,16 +517,27 @@ func GetLoggerGroup(names ...string) (loggerGroup *LoggerGroup) {
 		loggers: make(map[string]*logger),
 	}
 	for _, name := range names {
-		logger, ok := loggers[name]
-		if !ok {
-			loggerGroup.loggers[name] = defaultLogger
-		} else {
-			loggerGroup.loggers[name] = logger
-		}
+		loggerGroup.loggers[name] = findLogger(name)
 	}
 	return loggerGroup
 }
 
+// findLogger is find logger by dot separated name.
+// It returns nearest configured ancestor, or defaultLogger if nothing is found.
+// loggersMutex must be held by caller.
+func findLogger(name string) (l *logger) {
+	for {
+		if logger, ok := loggers[name]; ok {
+			return logger
+		}
+		idx := strings.LastIndex(name, ".")
+		if idx < 0 {
+			return defaultLogger
+		}
+		name = name[:idx]
+	}
+}
+
 func updateDefaultLogger(filter Filter, formatter Formatter, handlers []Handler) (err error) {
 	err = defaultLogger.changeFilter(filter)
 	if err != nil {
@@ -218,7 +554,8 @@ func updateDefaultLogger(filter Filter, formatter Formatter, handlers []Handler)
 	return nil
 }
 
-//SetLogger is set logger
+//SetLogger is set logger.
+//If the name is already set, the logger is changed in place and logger groups already got follow it.
 func SetLogger(name string, filter Filter, formatter Formatter, handlers []Handler) (err error) {
 	if  filter == nil || formatter == nil || handlers == nil || len(handlers) == 0 {
 		return errors.Errorf("invalid argument")
@@ -234,24 +571,40 @@ func SetLogger(name string, filter Filter, formatter Formatter, handlers []Handl
 	loggersMutex.Lock()
 	defer loggersMutex.Unlock()
 	if logger, ok := loggers[name]; ok {
-		// overwrite
-		for _, handler := range logger.handlers {
-			if handler.IsOpened() {
-				handler.Close()
-			}
-		}
+		// overwrite in place, so logger groups and named loggers already got follow new settings
+		logger.mutex.Lock()
+		defer logger.mutex.Unlock()
+		logger.filter = filter
+		logger.formatter = formatter
+		logger.swapHandlers(handlers)
+		return nil
 	}
 	loggers[name] = &logger{
 		filter:    filter,
 		formatter: formatter,
 		handlers:  handlers,
+		counters:  new(loggerCounters),
 		mutex:     new(sync.RWMutex),
 	}
-	for _, handler := range handlers {
-		if !handler.IsOpened() {
-			handler.Open()
-		}
+	acquireHandlers(handlers)
+	return nil
+}
+
+//RemoveLogger is remove logger. queued log events are written and handlers are closed.
+//log events of removed logger are written by parent logger or default logger.
+func RemoveLogger(name string) (err error) {
+	if name == "default" {
+		return errors.Errorf("can not remove default logger")
 	}
+	loggersMutex.Lock()
+	logger, ok := loggers[name]
+	if !ok {
+		loggersMutex.Unlock()
+		return errors.Errorf("not found logger (%v)", name)
+	}
+	delete(loggers, name)
+	loggersMutex.Unlock()
+	logger.close()
 	return nil
 }
 
@@ -259,7 +612,11 @@ func SetLogger(name string, filter Filter, formatter Formatter, handlers []Handl
 // default logger wrapper
 //
 
-func logBase(logLevel LogLevel, message string) {
+func logBase(callerSkip int, ctx context.Context, logLevel LogLevel, message string, keysAndValues []interface{}) {
+	if !IsEnabled(logLevel) {
+		defaultLogger.countFiltered()
+		return
+	}
 	logInfo := &logInfo{
 		program:  program,
 		pid:      pid,
@@ -268,58 +625,293 @@ func logBase(logLevel LogLevel, message string) {
 		logLevel: logLevel,
 		message:  message,
 	}
-	pc, fileName, lineNum, ok := runtime.Caller(2)
+	pc, fileName, lineNum, ok := runtime.Caller(2 + callerSkip)
 	if ok {
 		logInfo.pc = pc
 		logInfo.fileName = fileName
 		logInfo.lineNum = lineNum
 	}
+	if defaultLogger.isStackTraceEnabled(logLevel) {
+		logInfo.stackTrace = captureStackTrace(2 + callerSkip)
+	}
+	logInfo.setKeysAndValues(extractContext(ctx))
+	logInfo.setKeysAndValues(keysAndValues)
 	defaultLogger.log("default", logInfo)
 }
 
+//IsEnabled is check that log level is enabled by default logger
+func IsEnabled(logLevel LogLevel) (enabled bool) {
+	return defaultLogger.isEnabled("default", logLevel)
+}
+
 //Emerg is output log of emergency level with default logger
 func Emerg(format string, args ...interface{}) {
-	logBase(LogLevelEmerg, fmt.Sprintf(format, args...))
+	logBase(0, nil, LogLevelEmerg, fmt.Sprintf(format, args...), nil)
 }
 
 //Alert is output log of alert level with default logger
 func Alert(format string, args ...interface{}) {
-	logBase(LogLevelAlert, fmt.Sprintf(format, args...))
+	logBase(0, nil, LogLevelAlert, fmt.Sprintf(format, args...), nil)
 }
 
 //Crit is output log of critical level with default logger
 func Crit(format string, args ...interface{}) {
-	logBase(LogLevelCrit, fmt.Sprintf(format, args...))
+	logBase(0, nil, LogLevelCrit, fmt.Sprintf(format, args...), nil)
 }
 
 //Error is output log of error level with default logger
 func Error(format string, args ...interface{}) {
-	logBase(LogLevelError, fmt.Sprintf(format, args...))
+	logBase(0, nil, LogLevelError, fmt.Sprintf(format, args...), nil)
 }
 
 //Warn is output log of warning level with default logger
 func Warn(format string, args ...interface{}) {
-	logBase(LogLevelWarn, fmt.Sprintf(format, args...))
+	logBase(0, nil, LogLevelWarn, fmt.Sprintf(format, args...), nil)
 }
 
 //Notice is output log of notice level with default logger
 func Notice(format string, args ...interface{}) {
-	logBase(LogLevelNotice, fmt.Sprintf(format, args...))
+	logBase(0, nil, LogLevelNotice, fmt.Sprintf(format, args...), nil)
 }
 
 //Info is output log of info level with default logger
 func Info(format string, args ...interface{}) {
-	logBase(LogLevelInfo, fmt.Sprintf(format, args...))
+	logBase(0, nil, LogLevelInfo, fmt.Sprintf(format, args...), nil)
 }
 
 //Debug is output log of debug level with default logger
 func Debug(format string, args ...interface{}) {
-	logBase(LogLevelDebug, fmt.Sprintf(format, args...))
+	logBase(0, nil, LogLevelDebug, fmt.Sprintf(format, args...), nil)
 }
 
 //Trace is output log of trace level with default logger
 func Trace(format string, args ...interface{}) {
-	logBase(LogLevelTrace, fmt.Sprintf(format, args...))
+	logBase(0, nil, LogLevelTrace, fmt.Sprintf(format, args...), nil)
+}
+
+//Emergw is output log of emergency level with key value pairs with default logger
+func Emergw(message string, keysAndValues ...interface{}) {
+	logBase(0, nil, LogLevelEmerg, message, keysAndValues)
+}
+
+//Alertw is output log of alert level with key value pairs with default logger
+func Alertw(message string, keysAndValues ...interface{}) {
+	logBase(0, nil, LogLevelAlert, message, keysAndValues)
+}
+
+//Critw is output log of critical level with key value pairs with default logger
+func Critw(message string, keysAndValues ...interface{}) {
+	logBase(0, nil, LogLevelCrit, message, keysAndValues)
+}
+
+//Errorw is output log of error level with key value pairs with default logger
+func Errorw(message string, keysAndValues ...interface{}) {
+	logBase(0, nil, LogLevelError, message, keysAndValues)
+}
+
+//Warnw is output log of warning level with key value pairs with default logger
+func Warnw(message string, keysAndValues ...interface{}) {
+	logBase(0, nil, LogLevelWarn, message, keysAndValues)
+}
+
+//Noticew is output log of notice level with key value pairs with default logger
+func Noticew(message string, keysAndValues ...interface{}) {
+	logBase(0, nil, LogLevelNotice, message, keysAndValues)
+}
+
+//Infow is output log of info level with key value pairs with default logger
+func Infow(message string, keysAndValues ...interface{}) {
+	logBase(0, nil, LogLevelInfo, message, keysAndValues)
+}
+
+//Debugw is output log of debug level with key value pairs with default logger
+func Debugw(message string, keysAndValues ...interface{}) {
+	logBase(0, nil, LogLevelDebug, message, keysAndValues)
+}
+
+//Tracew is output log of trace level with key value pairs with default logger
+func Tracew(message string, keysAndValues ...interface{}) {
+	logBase(0, nil, LogLevelTrace, message, keysAndValues)
+}
+
+//EmergContext is output log of emergency level with values of context with default logger
+func EmergContext(ctx context.Context, format string, args ...interface{}) {
+	logBase(0, ctx, LogLevelEmerg, fmt.Sprintf(format, args...), nil)
+}
+
+//AlertContext is output log of alert level with values of context with default logger
+func AlertContext(ctx context.Context, format string, args ...interface{}) {
+	logBase(0, ctx, LogLevelAlert, fmt.Sprintf(format, args...), nil)
+}
+
+//CritContext is output log of critical level with values of context with default logger
+func CritContext(ctx context.Context, format string, args ...interface{}) {
+	logBase(0, ctx, LogLevelCrit, fmt.Sprintf(format, args...), nil)
+}
+
+//ErrorContext is output log of error level with values of context with default logger
+func ErrorContext(ctx context.Context, format string, args ...interface{}) {
+	logBase(0, ctx, LogLevelError, fmt.Sprintf(format, args...), nil)
+}
+
+//WarnContext is output log of warning level with values of context with default logger
+func WarnContext(ctx context.Context, format string, args ...interface{}) {
+	logBase(0, ctx, LogLevelWarn, fmt.Sprintf(format, args...), nil)
+}
+
+//NoticeContext is output log of notice level with values of context with default logger
+func NoticeContext(ctx context.Context, format string, args ...interface{}) {
+	logBase(0, ctx, LogLevelNotice, fmt.Sprintf(format, args...), nil)
+}
+
+//InfoContext is output log of info level with values of context with default logger
+func InfoContext(ctx context.Context, format string, args ...interface{}) {
+	logBase(0, ctx, LogLevelInfo, fmt.Sprintf(format, args...), nil)
+}
+
+//DebugContext is output log of debug level with values of context with default logger
+func DebugContext(ctx context.Context, format string, args ...interface{}) {
+	logBase(0, ctx, LogLevelDebug, fmt.Sprintf(format, args...), nil)
+}
+
+//TraceContext is output log of trace level with values of context with default logger
+func TraceContext(ctx context.Context, format string, args ...interface{}) {
+	logBase(0, ctx, LogLevelTrace, fmt.Sprintf(format, args...), nil)
+}
+
+//EmergFunc is output log of emergency level with default logger. messageFunc is called only if the level is enabled.
+func EmergFunc(messageFunc func() string) {
+	if !IsEnabled(LogLevelEmerg) {
+		defaultLogger.countFiltered()
+		return
+	}
+	logBase(0, nil, LogLevelEmerg, messageFunc(), nil)
+}
+
+//AlertFunc is output log of alert level with default logger. messageFunc is called only if the level is enabled.
+func AlertFunc(messageFunc func() string) {
+	if !IsEnabled(LogLevelAlert) {
+		defaultLogger.countFiltered()
+		return
+	}
+	logBase(0, nil, LogLevelAlert, messageFunc(), nil)
+}
+
+//CritFunc is output log of critical level with default logger. messageFunc is called only if the level is enabled.
+func CritFunc(messageFunc func() string) {
+	if !IsEnabled(LogLevelCrit) {
+		defaultLogger.countFiltered()
+		return
+	}
+	logBase(0, nil, LogLevelCrit, messageFunc(), nil)
+}
+
+//ErrorFunc is output log of error level with default logger. messageFunc is called only if the level is enabled.
+func ErrorFunc(messageFunc func() string) {
+	if !IsEnabled(LogLevelError) {
+		defaultLogger.countFiltered()
+		return
+	}
+	logBase(0, nil, LogLevelError, messageFunc(), nil)
+}
+
+//WarnFunc is output log of warning level with default logger. messageFunc is called only if the level is enabled.
+func WarnFunc(messageFunc func() string) {
+	if !IsEnabled(LogLevelWarn) {
+		defaultLogger.countFiltered()
+		return
+	}
+	logBase(0, nil, LogLevelWarn, messageFunc(), nil)
+}
+
+//NoticeFunc is output log of notice level with default logger. messageFunc is called only if the level is enabled.
+func NoticeFunc(messageFunc func() string) {
+	if !IsEnabled(LogLevelNotice) {
+		defaultLogger.countFiltered()
+		return
+	}
+	logBase(0, nil, LogLevelNotice, messageFunc(), nil)
+}
+
+//InfoFunc is output log of info level with default logger. messageFunc is called only if the level is enabled.
+func InfoFunc(messageFunc func() string) {
+	if !IsEnabled(LogLevelInfo) {
+		defaultLogger.countFiltered()
+		return
+	}
+	logBase(0, nil, LogLevelInfo, messageFunc(), nil)
+}
+
+//DebugFunc is output log of debug level with default logger. messageFunc is called only if the level is enabled.
+func DebugFunc(messageFunc func() string) {
+	if !IsEnabled(LogLevelDebug) {
+		defaultLogger.countFiltered()
+		return
+	}
+	logBase(0, nil, LogLevelDebug, messageFunc(), nil)
+}
+
+//TraceFunc is output log of trace level with default logger. messageFunc is called only if the level is enabled.
+func TraceFunc(messageFunc func() string) {
+	if !IsEnabled(LogLevelTrace) {
+		defaultLogger.countFiltered()
+		return
+	}
+	logBase(0, nil, LogLevelTrace, messageFunc(), nil)
+}
+
+//With is create logger group of default logger with bound key value pairs
+func With(keysAndValues ...interface{}) (loggerGroup *LoggerGroup) {
+	return GetLoggerGroup("default").With(keysAndValues...)
+}
+
+//Fatal is output log of emergency level with default logger, flush all loggers and exit
+func Fatal(format string, args ...interface{}) {
+	logBase(0, nil, LogLevelEmerg, fmt.Sprintf(format, args...), nil)
+	FlushAll()
+	getExitFunc()(1)
+}
+
+//Fatalw is output log of emergency level with key value pairs with default logger, flush all loggers and exit
+func Fatalw(message string, keysAndValues ...interface{}) {
+	logBase(0, nil, LogLevelEmerg, message, keysAndValues)
+	FlushAll()
+	getExitFunc()(1)
+}
+
+//Panic is output log of emergency level with default logger, flush all loggers and panic
+func Panic(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	logBase(0, nil, LogLevelEmerg, message, nil)
+	FlushAll()
+	panic(message)
+}
+
+//Panicw is output log of emergency level with key value pairs with default logger, flush all loggers and panic
+func Panicw(message string, keysAndValues ...interface{}) {
+	logBase(0, nil, LogLevelEmerg, message, keysAndValues)
+	FlushAll()
+	panic(message)
+}
+
+//WithError is create logger group of default logger with attached error
+func WithError(err error) (loggerGroup *LoggerGroup) {
+	return GetLoggerGroup("default").WithError(err)
+}
+
+//WithCallerSkip is create logger group of default logger that skips additional stack frames to find caller
+func WithCallerSkip(callerSkip int) (loggerGroup *LoggerGroup) {
+	return GetLoggerGroup("default").WithCallerSkip(callerSkip)
+}
+
+//Output is output log with default logger. callerSkip is count of additional stack frames to skip to find caller.
+func Output(callerSkip int, logLevel LogLevel, message string) {
+	logBase(callerSkip, nil, logLevel, message, nil)
+}
+
+//Outputw is output log with key value pairs with default logger. callerSkip is count of additional stack frames to skip to find caller.
+func Outputw(callerSkip int, logLevel LogLevel, message string, keysAndValues ...interface{}) {
+	logBase(callerSkip, nil, logLevel, message, keysAndValues)
 }
 
 //Flush is flush log of default logger
@@ -327,6 +919,32 @@ func Flush() {
 	defaultLogger.flush()
 }
 
+//FlushAll is flush log of default logger and all loggers
+func FlushAll() {
+	defaultLogger.flush()
+	loggersMutex.RLock()
+	defer loggersMutex.RUnlock()
+	for _, logger := range loggers {
+		logger.flush()
+	}
+}
+
+//SetExitFunc is set function called by Fatal. default is os.Exit.
+func SetExitFunc(newExitFunc func(code int)) {
+	if newExitFunc == nil {
+		newExitFunc = os.Exit
+	}
+	exitFuncMutex.Lock()
+	defer exitFuncMutex.Unlock()
+	exitFunc = newExitFunc
+}
+
+func getExitFunc() (currentExitFunc func(code int)) {
+	exitFuncMutex.RLock()
+	defer exitFuncMutex.RUnlock()
+	return exitFunc
+}
+
 //ChangeFilter is change filter of default logger
 func ChangeFilter(filter Filter) (err error) {
 	return defaultLogger.changeFilter(filter)
@@ -342,26 +960,111 @@ func ChangeHandlers(handlers []Handler) (err error) {
 	return defaultLogger.changeHandlers(handlers)
 }
 
+//ChangeStackTraceLogLevel is change log level to capture stack trace of default logger.
+//stack trace is captured for log event of this log level or more important. 0 is disabled.
+func ChangeStackTraceLogLevel(stackTraceLogLevel LogLevel) (err error) {
+	return defaultLogger.changeStackTraceLogLevel(stackTraceLogLevel)
+}
+
+//ChangeAsync is change async mode of default logger.
+//log events are written by worker goroutine through queue of queueSize. 0 is sync mode.
+func ChangeAsync(queueSize int, overflowPolicy OverflowPolicy) (err error) {
+	return defaultLogger.changeAsync(queueSize, overflowPolicy)
+}
+
+//Shutdown is flush and close handlers of all loggers and stop background goroutines and config watchers.
+//It returns error of ctx when ctx is done before completion.
+//log events after shutdown are written to stderr.
+func Shutdown(ctx context.Context) (err error) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stopConfigWatchers()
+		loggersMutex.RLock()
+		targets := make([]*logger, 0, len(loggers)+1)
+		targets = append(targets, defaultLogger)
+		for _, logger := range loggers {
+			targets = append(targets, logger)
+		}
+		loggersMutex.RUnlock()
+		for _, logger := range targets {
+			logger.flush()
+			logger.close()
+		}
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 //
 // logger
 //
 
 type logger struct {
-	filter    Filter
-	formatter Formatter
-	handlers  []Handler
-	mutex     *sync.RWMutex
+	filter             Filter
+	formatter          Formatter
+	handlers           []Handler
+	stackTraceLogLevel LogLevel
+	queue              *asyncQueue
+	closed             bool
+	counters           *loggerCounters
+	mutex              *sync.RWMutex
 }
 
 func (l *logger) log(loggerName string, logEvent LogEvent) {
 	l.mutex.RLock()
-	defer l.mutex.RUnlock()
-	if ok := l.filter.Evaluate(loggerName, logEvent); !ok {
+	ok := l.filter.Evaluate(loggerName, logEvent)
+	queue := l.queue
+	closed := l.closed
+	l.mutex.RUnlock()
+	if !ok {
+		l.countFiltered()
+		return
+	}
+	atomic.AddUint64(&l.counters.accepted, 1)
+	if closed {
+		// handlers are already closed
+		l.forward(loggerName, logEvent)
+		return
+	}
+	if queue != nil {
+		pushed, dropped := queue.push(loggerName, logEvent)
+		if dropped {
+			atomic.AddUint64(&l.counters.dropped, 1)
+			ReportError(loggerName, logEvent, "logger", errors.Errorf("queue overflow"))
+		}
+		if pushed {
+			return
+		}
+		// queue is closed
+	}
+	l.write(loggerName, logEvent)
+}
+
+// forward is write log event of closed logger by logger that currently resolves the name.
+// It is written to fallback logger if the name resolves this logger (e.g. after Shutdown).
+func (l *logger) forward(loggerName string, logEvent LogEvent) {
+	loggersMutex.RLock()
+	target := findLogger(loggerName)
+	loggersMutex.RUnlock()
+	if target == l {
+		fallbackLogger.write(loggerName, logEvent)
 		return
 	}
+	target.log(loggerName, logEvent)
+}
+
+func (l *logger) write(loggerName string, logEvent LogEvent) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
 	formattedLog, err := l.formatter.Format(loggerName, logEvent)
 	if err != nil {
-		// statistics
+		atomic.AddUint64(&l.counters.formatErrors, 1)
+		ReportError(loggerName, logEvent, componentName(l.formatter), err)
 		return
 	}
 	for _, handler := range l.handlers {
@@ -369,7 +1072,61 @@ func (l *logger) log(loggerName string, logEvent LogEvent) {
 	}
 }
 
+func (l *logger) asyncWorker(queue *asyncQueue) {
+	defer close(queue.stopped)
+	for {
+		event, ok := queue.pop()
+		if !ok {
+			return
+		}
+		if event.flushed != nil {
+			close(event.flushed)
+			continue
+		}
+		l.write(event.loggerName, event.logEvent)
+	}
+}
+
+func (l *logger) isEnabled(loggerName string, logLevel LogLevel) (enabled bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	levelFilter, ok := l.filter.(LevelFilter)
+	if !ok {
+		return true
+	}
+	return levelFilter.IsEnabled(loggerName, logLevel)
+}
+
+func (l *logger) isStackTraceEnabled(logLevel LogLevel) (enabled bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return logLevel <= l.stackTraceLogLevel
+}
+
+func (l *logger) countFiltered() {
+	atomic.AddUint64(&l.counters.filtered, 1)
+}
+
+func (l *logger) statistics() (statistics LoggerStatistics) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	statistics = l.counters.snapshot()
+	statistics.Handlers = make([]HandlerStatistics, 0, len(l.handlers))
+	for _, handler := range l.handlers {
+		statistics.Handlers = append(statistics.Handlers, handlerStatistics(handler))
+	}
+	return statistics
+}
+
 func (l *logger) flush() {
+	l.mutex.RLock()
+	queue := l.queue
+	l.mutex.RUnlock()
+	if queue != nil {
+		if flushed := queue.pushFlushMarker(); flushed != nil {
+			<-flushed
+		}
+	}
 	l.mutex.RLock()
 	defer l.mutex.RUnlock()
 	for _, handler := range l.handlers {
@@ -397,24 +1154,85 @@ func (l *logger) changeFormatter(formatter Formatter) (err error) {
 	return nil
 }
 
+func (l *logger) changeStackTraceLogLevel(stackTraceLogLevel LogLevel) (err error) {
+	if stackTraceLogLevel < 0 || stackTraceLogLevel > LogLevelTrace {
+		return errors.Errorf("invalid argument")
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.stackTraceLogLevel = stackTraceLogLevel
+	return nil
+}
+
+func (l *logger) changeAsync(queueSize int, overflowPolicy OverflowPolicy) (err error) {
+	if queueSize > 0 {
+		if _, ok := overflowPolicyMap[overflowPolicy]; !ok {
+			return errors.Errorf("invalid argument")
+		}
+	}
+	l.mutex.Lock()
+	oldQueue := l.swapQueue(queueSize, overflowPolicy)
+	l.mutex.Unlock()
+	if oldQueue != nil {
+		oldQueue.close()
+	}
+	return nil
+}
+
+// swapQueue is replace async queue and return old queue. old queue must be closed by caller.
+// mutex must be held by caller.
+func (l *logger) swapQueue(queueSize int, overflowPolicy OverflowPolicy) (oldQueue *asyncQueue) {
+	var newQueue *asyncQueue
+	if queueSize > 0 {
+		newQueue = newAsyncQueue(queueSize, overflowPolicy)
+		go l.asyncWorker(newQueue)
+	}
+	oldQueue = l.queue
+	l.queue = newQueue
+	return oldQueue
+}
+
+// close is stop async worker after writing queued log events and close handlers
+func (l *logger) close() {
+	l.changeAsync(0, 0)
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return
+	}
+	l.closed = true
+	releaseHandlers(l.handlers)
+}
+
 func (l *logger) changeHandlers(handlers []Handler) (err error) {
 	if handlers == nil || len(handlers) == 0 {
 		return errors.Errorf("invalid argument")
 	}
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
-	for _, handler := range l.handlers {
-		if handler.IsOpened() {
-			handler.Close()
-		}
+	l.swapHandlers(handlers)
+	return nil
+}
+
+// swapHandlers is open new handlers and close old handlers that are no longer used by any logger.
+// mutex must be held by caller.
+func (l *logger) swapHandlers(handlers []Handler) {
+	acquireHandlers(handlers)
+	if !l.closed {
+		releaseHandlers(l.handlers)
 	}
 	l.handlers = handlers
-	for _, handler := range l.handlers {
-		if !handler.IsOpened() {
-			handler.Open()
-		}
+	l.closed = false
+}
+
+// lookupLogger is get logger by exact name. It returns nil if not found.
+func lookupLogger(name string) (l *logger) {
+	if name == "default" {
+		return defaultLogger
 	}
-	return nil
+	loggersMutex.RLock()
+	defer loggersMutex.RUnlock()
+	return loggers[name]
 }
 
 func init() {
@@ -426,14 +1244,24 @@ func init() {
 	}
 	loggers = make(map[string]*logger)
 	loggersMutex = new(sync.RWMutex)
+	exitFuncMutex = new(sync.RWMutex)
 	h := NewConsoleHandler()
 	defaultLogger = &logger{
 		filter:    NewLogLevelFilter(),
 		formatter: NewStandardFormatter(),
 		handlers:  []Handler{h},
+		counters:  new(loggerCounters),
 		mutex:     new(sync.RWMutex),
 	}
-	if !h.IsOpened() {
-		h.Open()
+	acquireHandlers(defaultLogger.handlers)
+	fh := NewConsoleHandler()
+	fh.SetOutputType(ConsoleOutputTypeStderr)
+	fallbackLogger = &logger{
+		filter:    NewLogLevelFilter(),
+		formatter: NewStandardFormatter(),
+		handlers:  []Handler{fh},
+		counters:  new(loggerCounters),
+		mutex:     new(sync.RWMutex),
 	}
+	acquireHandlers(fallbackLogger.handlers)
 }
@@ -1,8 +1,13 @@
 package belog
 
 import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
 	"io/ioutil"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -560,3 +565,409 @@ func TestChangeLoggerGroup(t *testing.T) {
 	loggerGroup.Trace("test\n")
 	loggerGroup.Flush()
 }
+
+func TestDefaultLoggerContentKeysAndValues(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	filter.SetLogLevel(LogLevelTrace)
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(message)%(attrs)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := ChangeFilter(filter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeFormatter(formatter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeHandlers([]Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	Infow("test", "user", 10, "latency", 2*time.Second, "path", "/a b")
+	Errorw("test", "error", errors.New("failed"), 1, "odd")
+	Debugw("test\n")
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp := `datetime [INFO:7] default test latency=2s path="/a b" user=10
+datetime [ERROR:4] default test !BADKEY=1 error=failed odd=<nil>
+datetime [DEBUG:8] default test
+`
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+}
+
+func TestLoggerGroupWith(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	filter.SetLogLevel(LogLevelTrace)
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(message)%(attrs)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := SetLogger("logger1", filter, formatter, []Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	loggerGroup := GetLoggerGroup("logger1").With("requestID", "abc")
+	child := loggerGroup.With("component", "db")
+	child.Infow("test", "user", 10)
+	loggerGroup.Info("test")
+	child.Warnw("test", "component", "override")
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp := `datetime [INFO:7] logger1 test component=db requestID=abc user=10
+datetime [INFO:7] logger1 test requestID=abc
+datetime [WARN:5] logger1 test component=override requestID=abc
+`
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+}
+
+type testContextKey string
+
+func TestDefaultLoggerContext(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	RegisterContextExtractor("traceID", func(ctx context.Context) (value interface{}, ok bool) {
+		value = ctx.Value(testContextKey("traceID"))
+		return value, value != nil
+	})
+	defer UnregisterContextExtractor("traceID")
+	filter := NewLogLevelFilter()
+	filter.SetLogLevel(LogLevelTrace)
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(message)%(attrs)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := ChangeFilter(filter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeFormatter(formatter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeHandlers([]Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	ctx := context.WithValue(context.Background(), testContextKey("traceID"), "t1")
+	InfoContext(ctx, "test %v", 1)
+	InfoContext(context.Background(), "test %v", 2)
+	GetLoggerGroup("default").ErrorContext(ctx, "test %v", 3)
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp := `datetime [INFO:7] default test 1 traceID=t1
+datetime [INFO:7] default test 2
+datetime [ERROR:4] default test 3 traceID=t1
+`
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+}
+
+func TestGetLoggerFollowsSetLogger(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	filter.SetLogLevel(LogLevelTrace)
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(shortFileName) %(message)%(attrs)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test1.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := SetLogger("named", filter, formatter, []Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	logger := GetLogger("named.child")
+	logger.Info("test")
+	handler2 := NewRotationFileHandler()
+	handler2.SetLogFileName("belog-test2.log")
+	handler2.SetLogDirPath("/var/tmp/belog-test")
+	handler2.SetAsync(false)
+	if err := SetLogger("named", filter, formatter, []Handler{handler2}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	logger.With("user", 1).Warnw("test", "count", 2)
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test1.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp := "datetime [INFO:7] named.child logger_test.go test\n"
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+	b, err = ioutil.ReadFile("/var/tmp/belog-test/belog-test2.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp = "datetime [WARN:5] named.child logger_test.go test count=2 user=1\n"
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+}
+
+func TestLoggerGroupFollowsSetLogger(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	filter.SetLogLevel(LogLevelTrace)
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(shortFileName) %(message)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test1.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := SetLogger("group", filter, formatter, []Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	loggerGroup := GetLoggerGroup("group")
+	loggerGroup.Info("test1")
+	handler2 := NewRotationFileHandler()
+	handler2.SetLogFileName("belog-test2.log")
+	handler2.SetLogDirPath("/var/tmp/belog-test")
+	handler2.SetAsync(false)
+	if err := SetLogger("group", filter, formatter, []Handler{handler2}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	loggerGroup.Info("test2")
+	if handler1.IsOpened() {
+		t.Errorf("old handler is not closed")
+	}
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test1.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp := "datetime [INFO:7] group logger_test.go test1\n"
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+	b, err = ioutil.ReadFile("/var/tmp/belog-test/belog-test2.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp = "datetime [INFO:7] group logger_test.go test2\n"
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+}
+
+func TestDefaultLoggerIsEnabled(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	filter.SetLogLevel(LogLevelNotice)
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(shortFileName) %(message)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := ChangeFilter(filter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeFormatter(formatter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeHandlers([]Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if !IsEnabled(LogLevelNotice) {
+		t.Errorf("notice is not enabled")
+	}
+	if IsEnabled(LogLevelInfo) {
+		t.Errorf("info is enabled")
+	}
+	called := false
+	TraceFunc(func() string {
+		called = true
+		return "test"
+	})
+	if called {
+		t.Errorf("message func of disabled level is called")
+	}
+	WarnFunc(func() string {
+		return "test"
+	})
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp := "datetime [WARN:5] default logger_test.go test\n"
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+}
+
+func TestDefaultLoggerFatal(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	// Fatal and Panic are logged at emergency level, so they pass log level filter of any level
+	filter.SetLogLevel(LogLevelEmerg)
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(shortFileName) %(message)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(true)
+	handler1.SetBufferSize(2048)
+	if err := ChangeFilter(filter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeFormatter(formatter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeHandlers([]Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	exitCode := 0
+	SetExitFunc(func(code int) {
+		exitCode = code
+	})
+	defer SetExitFunc(nil)
+	Fatal("test %v", 1)
+	if exitCode != 1 {
+		t.Errorf("exit code mismatch (exp 1 != act %v)", exitCode)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != "test 2" {
+				t.Errorf("panic value mismatch (exp test 2 != act %v)", r)
+			}
+		}()
+		Panic("test %v", 2)
+	}()
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp := `datetime [EMERG:1] default logger_test.go test 1
+datetime [EMERG:1] default logger_test.go test 2
+`
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+}
+
+func logWrapper(message string) {
+	Output(1, LogLevelInfo, message)
+}
+
+func TestDefaultLoggerCallerSkip(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(shortFileName) %(lineNum) %(message)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := ChangeFilter(filter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeFormatter(formatter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeHandlers([]Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	_, _, lineNum, _ := runtime.Caller(0)
+	logWrapper("test")
+	wrapper := WithCallerSkip(1)
+	func() {
+		wrapper.Info("test")
+	}()
+	GetLogger("default").WithCallerSkip(0).Output(0, LogLevelInfo, "test")
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp := fmt.Sprintf(`datetime [INFO:7] default logger_test.go %v test
+datetime [INFO:7] default logger_test.go %v test
+datetime [INFO:7] default logger_test.go %v test
+`, lineNum+1, lineNum+5, lineNum+6)
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+}
+
+func TestDefaultLoggerStackTrace(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(message)%(stackTrace)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := ChangeFilter(filter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeFormatter(formatter); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeHandlers([]Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := ChangeStackTraceLogLevel(LogLevelCrit); err != nil {
+		t.Errorf("%+v", err)
+	}
+	defer ChangeStackTraceLogLevel(0)
+	Error("test")
+	Crit("test")
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	lines := strings.Split(string(b), "\n")
+	if len(lines) < 4 {
+		t.Fatalf("mismatch log (%v)", string(b))
+	}
+	if lines[0] != "datetime [ERROR:4] default test" || lines[1] != "datetime [CRIT:3] default test" {
+		t.Errorf("mismatch log (%v)", string(b))
+	}
+	if lines[2] != "github.com/potix/belog.TestDefaultLoggerStackTrace" || !strings.Contains(lines[3], "logger_test.go") {
+		t.Errorf("mismatch stack trace (%v)", string(b))
+	}
+}
+
+func TestNamedLoggerChangeNotConfigured(t *testing.T) {
+	filter := NewLogLevelFilter()
+	filter.SetLogLevel(LogLevelTrace)
+	if err := SetLogger("changeNamed", filter, NewStandardFormatter(), []Handler{NewConsoleHandler()}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	defaultFilter := defaultLogger.filter
+	logger := GetLogger("changeNamed.child")
+	if err := logger.ChangeFilter(NewLogLevelFilter()); err == nil {
+		t.Errorf("no error")
+	}
+	if err := logger.ChangeAsync(2, OverflowPolicyBlock); err == nil {
+		t.Errorf("no error")
+	}
+	if lookupLogger("changeNamed").filter != filter || defaultLogger.filter != defaultFilter {
+		t.Errorf("filter of ancestor logger is changed")
+	}
+	if err := GetLogger("changeNamed").ChangeStackTraceLogLevel(LogLevelCrit); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := RemoveLogger("changeNamed"); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
@@ -24,6 +24,19 @@ func (f *LogLevelFilter) Evaluate(loggerName string, logEvent LogEvent) (ok bool
 	return f.chainFilter.Evaluate(loggerName, logEvent)
 }
 
+//IsEnabled is check log level without log event
+func (f *LogLevelFilter) IsEnabled(loggerName string, logLevel LogLevel) (enabled bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	if logLevel > f.logLevel {
+		return false
+	}
+	if levelFilter, ok := f.chainFilter.(LevelFilter); ok {
+		return levelFilter.IsEnabled(loggerName, logLevel)
+	}
+	return true
+}
+
 //SetLogLevel is set logger level. outputs the important than this log level.
 func (f *LogLevelFilter) SetLogLevel(logLevel LogLevel) {
 	f.mutex.Lock()
@@ -38,6 +51,19 @@ func (f *LogLevelFilter) SetChainFilter(chainFilter Filter) {
 	f.chainFilter = chainFilter
 }
 
+//ExportSetters is export current settings as setters of config
+func (f *LogLevelFilter) ExportSetters() (setters []ConfigSetter) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	setters = []ConfigSetter{
+		{SetterName: "SetLogLevel", SetterParams: []interface{}{logLevelName(f.logLevel)}},
+	}
+	if f.chainFilter != nil {
+		setters = append(setters, ConfigSetter{SetterName: "SetChainFilter", SetterParams: []interface{}{f.chainFilter}})
+	}
+	return setters
+}
+
 //NewLogLevelFilter is create LogLevelFilter
 func NewLogLevelFilter() (logLevelFilter *LogLevelFilter) {
 	return &LogLevelFilter{
@@ -50,4 +76,5 @@ func init() {
 	RegisterFilter("LogLevelFilter", func() (filter Filter) {
 		return NewLogLevelFilter()
 	})
+	recordComponentType("LogLevelFilter", (*LogLevelFilter)(nil))
 }
@@ -0,0 +1,444 @@
+package belog
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"runtime"
+	"time"
+)
+
+//
+// named logger
+//
+
+//Logger is handle of named logger.
+//It looks up the logger by name on every call, so it follows the logger replaced by SetLogger or LoadConfig.
+type Logger struct {
+	name          string
+	keysAndValues []interface{}
+	callerSkip    int
+	err           error
+}
+
+func (l *Logger) current() (logger *logger) {
+	if l.name == "default" {
+		return defaultLogger
+	}
+	loggersMutex.RLock()
+	defer loggersMutex.RUnlock()
+	return findLogger(l.name)
+}
+
+// configured is get logger configured with exactly the name of named logger
+func (l *Logger) configured() (logger *logger, err error) {
+	logger = lookupLogger(l.name)
+	if logger == nil {
+		return nil, errors.Errorf("not found logger (%v)", l.name)
+	}
+	return logger, nil
+}
+
+func (l *Logger) logBase(callerSkip int, ctx context.Context, logLevel LogLevel, message string, keysAndValues []interface{}) {
+	current := l.current()
+	if !current.isEnabled(l.name, logLevel) {
+		current.countFiltered()
+		return
+	}
+	logInfo := &logInfo{
+		program:  program,
+		pid:      pid,
+		hostname: hostname,
+		time:     time.Now(),
+		logLevel: logLevel,
+		message:  message,
+	}
+	pc, fileName, lineNum, ok := runtime.Caller(2 + callerSkip + l.callerSkip)
+	if ok {
+		logInfo.pc = pc
+		logInfo.fileName = fileName
+		logInfo.lineNum = lineNum
+	}
+	if current.isStackTraceEnabled(logLevel) {
+		logInfo.stackTrace = captureStackTrace(2 + callerSkip + l.callerSkip)
+	}
+	logInfo.err = l.err
+	logInfo.setKeysAndValues(l.keysAndValues)
+	logInfo.setKeysAndValues(extractContext(ctx))
+	logInfo.setKeysAndValues(keysAndValues)
+	current.log(l.name, logInfo)
+}
+
+//IsEnabled is check that log level is enabled by named logger
+func (l *Logger) IsEnabled(logLevel LogLevel) (enabled bool) {
+	return l.current().isEnabled(l.name, logLevel)
+}
+
+//Emerg is output log of emergency level with named logger
+func (l *Logger) Emerg(format string, args ...interface{}) {
+	l.logBase(0, nil, LogLevelEmerg, fmt.Sprintf(format, args...), nil)
+}
+
+//Alert is output log of alert level with named logger
+func (l *Logger) Alert(format string, args ...interface{}) {
+	l.logBase(0, nil, LogLevelAlert, fmt.Sprintf(format, args...), nil)
+}
+
+//Crit is output log of critical level with named logger
+func (l *Logger) Crit(format string, args ...interface{}) {
+	l.logBase(0, nil, LogLevelCrit, fmt.Sprintf(format, args...), nil)
+}
+
+//Error is output log of error level with named logger
+func (l *Logger) Error(format string, args ...interface{}) {
+	l.logBase(0, nil, LogLevelError, fmt.Sprintf(format, args...), nil)
+}
+
+//Warn is output log of warning level with named logger
+func (l *Logger) Warn(format string, args ...interface{}) {
+	l.logBase(0, nil, LogLevelWarn, fmt.Sprintf(format, args...), nil)
+}
+
+//Notice is output log of notice level with named logger
+func (l *Logger) Notice(format string, args ...interface{}) {
+	l.logBase(0, nil, LogLevelNotice, fmt.Sprintf(format, args...), nil)
+}
+
+//Info is output log of info level with named logger
+func (l *Logger) Info(format string, args ...interface{}) {
+	l.logBase(0, nil, LogLevelInfo, fmt.Sprintf(format, args...), nil)
+}
+
+//Debug is output log of debug level with named logger
+func (l *Logger) Debug(format string, args ...interface{}) {
+	l.logBase(0, nil, LogLevelDebug, fmt.Sprintf(format, args...), nil)
+}
+
+//Trace is output log of trace level with named logger
+func (l *Logger) Trace(format string, args ...interface{}) {
+	l.logBase(0, nil, LogLevelTrace, fmt.Sprintf(format, args...), nil)
+}
+
+//Emergw is output log of emergency level with key value pairs with named logger
+func (l *Logger) Emergw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelEmerg, message, keysAndValues)
+}
+
+//Alertw is output log of alert level with key value pairs with named logger
+func (l *Logger) Alertw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelAlert, message, keysAndValues)
+}
+
+//Critw is output log of critical level with key value pairs with named logger
+func (l *Logger) Critw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelCrit, message, keysAndValues)
+}
+
+//Errorw is output log of error level with key value pairs with named logger
+func (l *Logger) Errorw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelError, message, keysAndValues)
+}
+
+//Warnw is output log of warning level with key value pairs with named logger
+func (l *Logger) Warnw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelWarn, message, keysAndValues)
+}
+
+//Noticew is output log of notice level with key value pairs with named logger
+func (l *Logger) Noticew(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelNotice, message, keysAndValues)
+}
+
+//Infow is output log of info level with key value pairs with named logger
+func (l *Logger) Infow(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelInfo, message, keysAndValues)
+}
+
+//Debugw is output log of debug level with key value pairs with named logger
+func (l *Logger) Debugw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelDebug, message, keysAndValues)
+}
+
+//Tracew is output log of trace level with key value pairs with named logger
+func (l *Logger) Tracew(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelTrace, message, keysAndValues)
+}
+
+//EmergContext is output log of emergency level with values of context with named logger
+func (l *Logger) EmergContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelEmerg, fmt.Sprintf(format, args...), nil)
+}
+
+//AlertContext is output log of alert level with values of context with named logger
+func (l *Logger) AlertContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelAlert, fmt.Sprintf(format, args...), nil)
+}
+
+//CritContext is output log of critical level with values of context with named logger
+func (l *Logger) CritContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelCrit, fmt.Sprintf(format, args...), nil)
+}
+
+//ErrorContext is output log of error level with values of context with named logger
+func (l *Logger) ErrorContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelError, fmt.Sprintf(format, args...), nil)
+}
+
+//WarnContext is output log of warning level with values of context with named logger
+func (l *Logger) WarnContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelWarn, fmt.Sprintf(format, args...), nil)
+}
+
+//NoticeContext is output log of notice level with values of context with named logger
+func (l *Logger) NoticeContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelNotice, fmt.Sprintf(format, args...), nil)
+}
+
+//InfoContext is output log of info level with values of context with named logger
+func (l *Logger) InfoContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelInfo, fmt.Sprintf(format, args...), nil)
+}
+
+//DebugContext is output log of debug level with values of context with named logger
+func (l *Logger) DebugContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelDebug, fmt.Sprintf(format, args...), nil)
+}
+
+//TraceContext is output log of trace level with values of context with named logger
+func (l *Logger) TraceContext(ctx context.Context, format string, args ...interface{}) {
+	l.logBase(0, ctx, LogLevelTrace, fmt.Sprintf(format, args...), nil)
+}
+
+//EmergFunc is output log of emergency level with named logger. messageFunc is called only if the level is enabled.
+func (l *Logger) EmergFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelEmerg) {
+		l.current().countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelEmerg, messageFunc(), nil)
+}
+
+//AlertFunc is output log of alert level with named logger. messageFunc is called only if the level is enabled.
+func (l *Logger) AlertFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelAlert) {
+		l.current().countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelAlert, messageFunc(), nil)
+}
+
+//CritFunc is output log of critical level with named logger. messageFunc is called only if the level is enabled.
+func (l *Logger) CritFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelCrit) {
+		l.current().countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelCrit, messageFunc(), nil)
+}
+
+//ErrorFunc is output log of error level with named logger. messageFunc is called only if the level is enabled.
+func (l *Logger) ErrorFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelError) {
+		l.current().countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelError, messageFunc(), nil)
+}
+
+//WarnFunc is output log of warning level with named logger. messageFunc is called only if the level is enabled.
+func (l *Logger) WarnFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelWarn) {
+		l.current().countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelWarn, messageFunc(), nil)
+}
+
+//NoticeFunc is output log of notice level with named logger. messageFunc is called only if the level is enabled.
+func (l *Logger) NoticeFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelNotice) {
+		l.current().countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelNotice, messageFunc(), nil)
+}
+
+//InfoFunc is output log of info level with named logger. messageFunc is called only if the level is enabled.
+func (l *Logger) InfoFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelInfo) {
+		l.current().countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelInfo, messageFunc(), nil)
+}
+
+//DebugFunc is output log of debug level with named logger. messageFunc is called only if the level is enabled.
+func (l *Logger) DebugFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelDebug) {
+		l.current().countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelDebug, messageFunc(), nil)
+}
+
+//TraceFunc is output log of trace level with named logger. messageFunc is called only if the level is enabled.
+func (l *Logger) TraceFunc(messageFunc func() string) {
+	if !l.IsEnabled(LogLevelTrace) {
+		l.current().countFiltered()
+		return
+	}
+	l.logBase(0, nil, LogLevelTrace, messageFunc(), nil)
+}
+
+//Name is return logger name
+func (l *Logger) Name() (name string) {
+	return l.name
+}
+
+//With is create named logger with bound key value pairs.
+//bound key value pairs are set to every log event of created logger.
+func (l *Logger) With(keysAndValues ...interface{}) (logger *Logger) {
+	boundKeysAndValues := make([]interface{}, 0, len(l.keysAndValues)+len(keysAndValues))
+	boundKeysAndValues = append(boundKeysAndValues, l.keysAndValues...)
+	boundKeysAndValues = append(boundKeysAndValues, keysAndValues...)
+	return &Logger{
+		name:          l.name,
+		keysAndValues: boundKeysAndValues,
+		callerSkip:    l.callerSkip,
+		err:           l.err,
+	}
+}
+
+//WithError is create named logger with attached error.
+//message, causes and stack trace of the error are output by formatter.
+func (l *Logger) WithError(err error) (logger *Logger) {
+	return &Logger{
+		name:          l.name,
+		keysAndValues: l.keysAndValues,
+		callerSkip:    l.callerSkip,
+		err:           err,
+	}
+}
+
+//WithCallerSkip is create named logger that skips additional stack frames to find caller.
+//It is useful for wrapper function of named logger.
+func (l *Logger) WithCallerSkip(callerSkip int) (logger *Logger) {
+	return &Logger{
+		name:          l.name,
+		keysAndValues: l.keysAndValues,
+		callerSkip:    l.callerSkip + callerSkip,
+		err:           l.err,
+	}
+}
+
+//Output is output log with named logger. callerSkip is count of additional stack frames to skip to find caller.
+func (l *Logger) Output(callerSkip int, logLevel LogLevel, message string) {
+	l.logBase(callerSkip, nil, logLevel, message, nil)
+}
+
+//Outputw is output log with key value pairs with named logger. callerSkip is count of additional stack frames to skip to find caller.
+func (l *Logger) Outputw(callerSkip int, logLevel LogLevel, message string, keysAndValues ...interface{}) {
+	l.logBase(callerSkip, nil, logLevel, message, keysAndValues)
+}
+
+//Fatal is output log of emergency level with named logger, flush all loggers and exit
+func (l *Logger) Fatal(format string, args ...interface{}) {
+	l.logBase(0, nil, LogLevelEmerg, fmt.Sprintf(format, args...), nil)
+	FlushAll()
+	getExitFunc()(1)
+}
+
+//Fatalw is output log of emergency level with key value pairs with named logger, flush all loggers and exit
+func (l *Logger) Fatalw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelEmerg, message, keysAndValues)
+	FlushAll()
+	getExitFunc()(1)
+}
+
+//Panic is output log of emergency level with named logger, flush all loggers and panic
+func (l *Logger) Panic(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	l.logBase(0, nil, LogLevelEmerg, message, nil)
+	FlushAll()
+	panic(message)
+}
+
+//Panicw is output log of emergency level with key value pairs with named logger, flush all loggers and panic
+func (l *Logger) Panicw(message string, keysAndValues ...interface{}) {
+	l.logBase(0, nil, LogLevelEmerg, message, keysAndValues)
+	FlushAll()
+	panic(message)
+}
+
+//Flush is flush log of named logger
+func (l *Logger) Flush() {
+	l.current().flush()
+}
+
+//ChangeFilter is change filter of named logger.
+//It returns error if the name is not configured.
+func (l *Logger) ChangeFilter(filter Filter) (err error) {
+	logger, err := l.configured()
+	if err != nil {
+		return err
+	}
+	return logger.changeFilter(filter)
+}
+
+//ChangeFormatter is change formatter of named logger.
+//It returns error if the name is not configured.
+func (l *Logger) ChangeFormatter(formatter Formatter) (err error) {
+	logger, err := l.configured()
+	if err != nil {
+		return err
+	}
+	return logger.changeFormatter(formatter)
+}
+
+//ChangeHandlers is change handlers of named logger.
+//It returns error if the name is not configured.
+func (l *Logger) ChangeHandlers(handlers []Handler) (err error) {
+	logger, err := l.configured()
+	if err != nil {
+		return err
+	}
+	return logger.changeHandlers(handlers)
+}
+
+//ChangeStackTraceLogLevel is change log level to capture stack trace of named logger.
+//It returns error if the name is not configured.
+func (l *Logger) ChangeStackTraceLogLevel(stackTraceLogLevel LogLevel) (err error) {
+	logger, err := l.configured()
+	if err != nil {
+		return err
+	}
+	return logger.changeStackTraceLogLevel(stackTraceLogLevel)
+}
+
+//Statistics is get statistics of named logger.
+//If the name is not configured, it of resolved ancestor logger is returned.
+func (l *Logger) Statistics() (statistics LoggerStatistics) {
+	return l.current().statistics()
+}
+
+//SetErrorHandler is set error handler of named logger. It is used for descendant logger names too.
+func (l *Logger) SetErrorHandler(errorHandler ErrorHandler) {
+	SetLoggerErrorHandler(l.name, errorHandler)
+}
+
+//ChangeAsync is change async mode of named logger.
+//It returns error if the name is not configured.
+func (l *Logger) ChangeAsync(queueSize int, overflowPolicy OverflowPolicy) (err error) {
+	logger, err := l.configured()
+	if err != nil {
+		return err
+	}
+	return logger.changeAsync(queueSize, overflowPolicy)
+}
+
+//GetLogger is get named logger
+func GetLogger(name string) (logger *Logger) {
+	return &Logger{
+		name: name,
+	}
+}
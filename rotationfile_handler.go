@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -16,6 +17,13 @@ const (
 	rotationFileDirLayout                 = "2006-01-02"
 )
 
+// rotationFileError is error reported after mutex of handler is unlocked
+type rotationFileError struct {
+	loggerName string
+	logEvent   LogEvent
+	err        error
+}
+
 //RotationFileHandler is handler of file with rotation
 type RotationFileHandler struct {
 	logFileName        string
@@ -27,10 +35,12 @@ type RotationFileHandler struct {
 	bufferSize         int
 	buffer             *bytes.Buffer
 	lastLogEvent       LogEvent
-	scheduledFlush     bool
+	flushTimer         *time.Timer
 	logFileSize        int64
 	lastModifiedTime   time.Time
 	logFile            *os.File
+	pendingErrors      []*rotationFileError
+	counters           *handlerCounters
 	mutex              *sync.Mutex
 }
 
@@ -47,33 +57,40 @@ func (h *RotationFileHandler) IsOpened() (bool) {
 
 //Open is open file
 func (h *RotationFileHandler) Open() {
+	defer h.reportErrors()
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
-	h.openLogFile()
+	h.openLogFile("", nil)
 }
 
 //Write is write formatted log
 func (h *RotationFileHandler) Write(loggerName string, logEvent LogEvent, formattedLog string) {
+	defer h.reportErrors()
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 	if h.async {
-		lastLogEvent, logBuffer, full := h.pushBuffer(logEvent, formattedLog)
+		lastLogEvent, logBuffer, full := h.pushBuffer(loggerName, logEvent, formattedLog)
 		if full {
-			h.writeLog(lastLogEvent.Time(), logBuffer)
+			// buffer includes log events of other loggers
+			h.writeLog("", nil, lastLogEvent.Time(), logBuffer)
 		} else {
 			// timer flush
-			if !h.scheduledFlush {
-				h.scheduledFlush = true
-				go h.logBufferFlushTimer()
+			if h.flushTimer == nil {
+				interval := time.Duration(h.asyncFlushInterval) * time.Second
+				if interval <= 0 {
+					interval = rotationFileDefaultAsyncFlushInterval * time.Second
+				}
+				h.flushTimer = time.AfterFunc(interval, h.logBufferFlushTimer)
 			}
 		}
 	} else {
-		h.writeLog(logEvent.Time(), formattedLog)
+		h.writeLog(loggerName, logEvent, logEvent.Time(), formattedLog)
 	}
 }
 
 //Flush is flush buffer and sync
 func (h *RotationFileHandler) Flush() {
+	defer h.reportErrors()
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 	if h.async {
@@ -82,15 +99,17 @@ func (h *RotationFileHandler) Flush() {
 	if h.logFile != nil {
 		err := h.logFile.Sync()
 		if err != nil {
-			// statistics
+			h.writeError("", nil, err)
 		}
 	}
 }
 
 //Close is close File
 func (h *RotationFileHandler) Close() {
+	defer h.reportErrors()
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
+	h.stopFlushTimer()
 	if h.logFile == nil {
 		return
 	}
@@ -99,13 +118,41 @@ func (h *RotationFileHandler) Close() {
 	}
 	err := h.logFile.Close()
 	if err != nil {
-		// statistics
+		h.writeError("", nil, err)
 	}
 	h.logFile = nil
 	h.lastModifiedTime = time.Time{}
 	h.logFileSize = 0
 }
 
+// writeError is count error and keep it to report after mutex is unlocked.
+// loggerName is empty and logEvent is nil when the error is not caused by log event of specific logger.
+func (h *RotationFileHandler) writeError(loggerName string, logEvent LogEvent, err error) {
+	h.counters.writeError()
+	h.pendingErrors = append(h.pendingErrors, &rotationFileError{
+		loggerName: loggerName,
+		logEvent:   logEvent,
+		err:        err,
+	})
+}
+
+// reportErrors is report kept errors. mutex must not be held by caller,
+// so that error handler can output log with this handler.
+func (h *RotationFileHandler) reportErrors() {
+	h.mutex.Lock()
+	pendingErrors := h.pendingErrors
+	h.pendingErrors = nil
+	h.mutex.Unlock()
+	for _, pendingError := range pendingErrors {
+		ReportError(pendingError.loggerName, pendingError.logEvent, "RotationFileHandler", pendingError.err)
+	}
+}
+
+//Statistics is get statistics of handler
+func (h *RotationFileHandler) Statistics() (statistics HandlerStatistics) {
+	return h.counters.snapshot("RotationFileHandler")
+}
+
 //SetLogFileName is set log file name
 func (h *RotationFileHandler) SetLogFileName(logFileName string) {
 	h.mutex.Lock()
@@ -162,51 +209,61 @@ func (h *RotationFileHandler) SetBufferSize(bufferSize int) {
 }
 
 func (h *RotationFileHandler) logBufferFlushTimer() {
-	time.Sleep(time.Second)
+	defer h.reportErrors()
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
+	h.flushTimer = nil
 	h.logBufferFlush()
-	h.scheduledFlush = false
+}
+
+func (h *RotationFileHandler) stopFlushTimer() {
+	if h.flushTimer == nil {
+		return
+	}
+	h.flushTimer.Stop()
+	h.flushTimer = nil
 }
 
 func (h *RotationFileHandler) logBufferFlush() {
 	lastLogEvent, logBuffer, remain := h.popBuffer()
 	if remain {
-		h.writeLog(lastLogEvent.Time(), logBuffer)
+		h.writeLog("", nil, lastLogEvent.Time(), logBuffer)
 	}
 }
 
-func (h *RotationFileHandler) writeLog(logTime time.Time, logBuffer string) {
-	h.openLogFile()
-	h.rotateLogFile(logTime)
+func (h *RotationFileHandler) writeLog(loggerName string, logEvent LogEvent, logTime time.Time, logBuffer string) {
+	h.openLogFile(loggerName, logEvent)
+	h.rotateLogFile(loggerName, logEvent, logTime)
 	if h.logFile == nil {
-		// statistics
+		h.counters.drop()
 		return
 	}
 	wlen, err := h.logFile.WriteString(logBuffer)
 	if err != nil {
-		// sstatistics
+		h.writeError(loggerName, logEvent, err)
+		h.counters.drop()
 		return
 	}
+	h.counters.write(wlen)
 	h.lastModifiedTime = logTime
 	h.logFileSize += int64(wlen)
 }
 
-func (h *RotationFileHandler) openLogFile() {
+func (h *RotationFileHandler) openLogFile(loggerName string, logEvent LogEvent) {
 	if h.logFile != nil {
 		return
 	}
 	// make directories
 	err := os.MkdirAll(h.logDirPath, os.FileMode(0755))
 	if err != nil {
-		// statistics
+		h.writeError(loggerName, logEvent, err)
 		return
 	}
 	// open log file
 	logFilePath := filepath.Join(h.logDirPath, h.logFileName)
 	file, err := os.OpenFile(logFilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, os.FileMode(0644))
 	if err != nil {
-		// statisticsa
+		h.writeError(loggerName, logEvent, err)
 		return
 	}
 	h.logFile = file
@@ -216,7 +273,7 @@ func (h *RotationFileHandler) openLogFile() {
 	h.logFileSize = fileInfo.Size()
 }
 
-func (h *RotationFileHandler) rotateLogFile(lastLogTime time.Time) {
+func (h *RotationFileHandler) rotateLogFile(loggerName string, logEvent LogEvent, lastLogTime time.Time) {
 	if (h.lastModifiedTime.Year() == lastLogTime.Year() &&
 		h.lastModifiedTime.YearDay() == lastLogTime.YearDay()) &&
 		(h.maxSize <= 0 || h.logFileSize < h.maxSize) {
@@ -226,27 +283,28 @@ func (h *RotationFileHandler) rotateLogFile(lastLogTime time.Time) {
 	// get rotated file path
 	rotatedLogDirPath, rotatedLogFilePath := h.getRotatedLogFilePath()
 	if err := os.MkdirAll(rotatedLogDirPath, os.FileMode(0755)); err != nil {
-		// statistics
+		h.writeError(loggerName, logEvent, err)
 		return
 	}
 	// rename
 	if err := os.Rename(logFilePath, rotatedLogFilePath); err != nil {
-		// statistics
+		h.writeError(loggerName, logEvent, err)
 		return
 	}
 	// open new log file
 	file, err := os.OpenFile(logFilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, os.FileMode(0644))
 	if err != nil {
-		// statistics
+		h.writeError(loggerName, logEvent, err)
 		return
 	}
 	if err := h.logFile.Close(); err != nil {
-		// statistics
+		h.writeError(loggerName, logEvent, err)
 	}
+	h.counters.rotate()
 	h.logFile = file
 	h.lastModifiedTime = lastLogTime
 	h.logFileSize = 0
-	h.deleteOldLogFiles()
+	h.deleteOldLogFiles(loggerName, logEvent)
 }
 
 func (h *RotationFileHandler) getRotatedLogFilePath() (rotatedLogDirPath string, rotatedLogFilePath string) {
@@ -264,7 +322,7 @@ func (h *RotationFileHandler) getRotatedLogFilePath() (rotatedLogDirPath string,
 	}
 }
 
-func (h *RotationFileHandler) deleteOldLogFiles() {
+func (h *RotationFileHandler) deleteOldLogFiles(loggerName string, logEvent LogEvent) {
 	files, err := ioutil.ReadDir(h.logDirPath)
 	if err != nil {
 		return
@@ -278,22 +336,22 @@ func (h *RotationFileHandler) deleteOldLogFiles() {
 		}
 		dirTime, err := time.Parse(rotationFileDirLayout, file.Name())
 		if err != nil {
-			// statistics
+			// not rotated directory
 			continue
 		}
 		if dirTime.Before(oldAdjustTime) {
 			err := os.RemoveAll(filepath.Join(h.logDirPath, file.Name()))
 			if err != nil {
-				// statistics
+				h.writeError(loggerName, logEvent, err)
 			}
 		}
 	}
 }
 
-func (h *RotationFileHandler) pushBuffer(logEvent LogEvent, formattedLog string) (lastLogEvent LogEvent, logBuffer string, full bool) {
+func (h *RotationFileHandler) pushBuffer(loggerName string, logEvent LogEvent, formattedLog string) (lastLogEvent LogEvent, logBuffer string, full bool) {
 	_, err := h.buffer.WriteString(formattedLog)
 	if err != nil {
-		// statistics
+		h.writeError(loggerName, logEvent, err)
 	}
 	h.lastLogEvent = logEvent
 	if h.buffer.Len() > h.bufferSize {
@@ -317,6 +375,21 @@ func (h *RotationFileHandler) popBufferBase() (lastLogEvent LogEvent, logBuffer
 	return lastLogEvent, logBuffer, true
 }
 
+//ExportSetters is export current settings as setters of config
+func (h *RotationFileHandler) ExportSetters() (setters []ConfigSetter) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return []ConfigSetter{
+		{SetterName: "SetLogFileName", SetterParams: []interface{}{h.logFileName}},
+		{SetterName: "SetLogDirPath", SetterParams: []interface{}{h.logDirPath}},
+		{SetterName: "SetMaxAge", SetterParams: []interface{}{strconv.Itoa(h.maxAge)}},
+		{SetterName: "SetMaxSize", SetterParams: []interface{}{strconv.FormatInt(h.maxSize, 10)}},
+		{SetterName: "SetAsync", SetterParams: []interface{}{strconv.FormatBool(h.async)}},
+		{SetterName: "SetAsyncFlushInterval", SetterParams: []interface{}{strconv.Itoa(h.asyncFlushInterval)}},
+		{SetterName: "SetBufferSize", SetterParams: []interface{}{strconv.Itoa(h.bufferSize)}},
+	}
+}
+
 //NewRotationFileHandler is create RotationFileHandler
 func NewRotationFileHandler() (rotationFileHandler *RotationFileHandler) {
 	return &RotationFileHandler{
@@ -327,6 +400,7 @@ func NewRotationFileHandler() (rotationFileHandler *RotationFileHandler) {
 		asyncFlushInterval: rotationFileDefaultAsyncFlushInterval,
 		buffer:             bytes.NewBuffer(make([]byte, 0, rotationFileDefaultBufferSize)),
 		bufferSize:         rotationFileDefaultBufferSize,
+		counters:           new(handlerCounters),
 		mutex:              new(sync.Mutex),
 	}
 }
@@ -335,4 +409,5 @@ func init() {
 	RegisterHandler("RotationFileHandler", func() (handler Handler) {
 		return NewRotationFileHandler()
 	})
+	recordComponentType("RotationFileHandler", (*RotationFileHandler)(nil))
 }
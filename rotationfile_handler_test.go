@@ -100,3 +100,36 @@ func TestRotationFileCleanup(t *testing.T) {
 		t.Errorf("files count mismatch /var/tmp/belog-test/%v", dirName)
 	}
 }
+
+func TestRotationFileError(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	if err := os.MkdirAll("/var/tmp/belog-test", os.FileMode(0755)); err != nil {
+		t.Errorf("%+v", err)
+	}
+	// log directory can not be made under regular file
+	if err := ioutil.WriteFile("/var/tmp/belog-test/file", []byte{}, 0644); err != nil {
+		t.Errorf("%+v", err)
+	}
+	rotationFilehandler := NewRotationFileHandler()
+	rotationFilehandler.SetLogFileName("belog-test.log")
+	rotationFilehandler.SetLogDirPath("/var/tmp/belog-test/file/dir")
+	logInfo := &logInfo{
+		time: time.Now(),
+	}
+	reported := make([]string, 0)
+	SetErrorHandler(func(loggerName string, logEvent LogEvent, component string, err error) {
+		reported = append(reported, fmt.Sprintf("%v:%v", loggerName, logEvent != nil))
+		if len(reported) == 1 {
+			// error handler can output log with the handler
+			rotationFilehandler.Write("handler", logInfo, "error")
+		}
+	})
+	defer SetErrorHandler(nil)
+	rotationFilehandler.Write("test", logInfo, "test")
+	rotationFilehandler.Open()
+	// errors of making directory on opening and rotating are reported to each logger, and error of Open is reported without logger
+	exp := "[test:true handler:true handler:true test:true :false]"
+	if act := fmt.Sprint(reported); act != exp {
+		t.Errorf("reported mismatch (exp %v != act %v)", exp, act)
+	}
+}
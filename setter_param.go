@@ -0,0 +1,197 @@
+package belog
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//SetterParamParser is function to parse setter parameter of config into value of parameter type
+type SetterParamParser func(param string) (value interface{}, err error)
+
+var (
+	setterParamParsers      map[reflect.Type]SetterParamParser
+	setterParamParsersMutex *sync.RWMutex
+	byteSizeUnits           = []struct {
+		suffix string
+		size   uint64
+	}{
+		// longer suffix first
+		{"KIB", 1 << 10},
+		{"MIB", 1 << 20},
+		{"GIB", 1 << 30},
+		{"TIB", 1 << 40},
+		{"KB", 1000},
+		{"MB", 1000 * 1000},
+		{"GB", 1000 * 1000 * 1000},
+		{"TB", 1000 * 1000 * 1000 * 1000},
+		{"B", 1},
+	}
+)
+
+//RegisterSetterParamParser is register parser of setter parameter for paramType.
+//It takes precedence over builtin conversion of kind of paramType.
+func RegisterSetterParamParser(paramType reflect.Type, parser SetterParamParser) {
+	setterParamParsersMutex.Lock()
+	defer setterParamParsersMutex.Unlock()
+	setterParamParsers[paramType] = parser
+}
+
+func getSetterParamParser(paramType reflect.Type) (parser SetterParamParser, ok bool) {
+	setterParamParsersMutex.RLock()
+	defer setterParamParsersMutex.RUnlock()
+	parser, ok = setterParamParsers[paramType]
+	return parser, ok
+}
+
+// parseByteSize is parse size with unit (e.g. "64MiB", "10KB")
+func parseByteSize(param string) (size uint64, err error) {
+	s := strings.ToUpper(strings.TrimSpace(param))
+	for _, unit := range byteSizeUnits {
+		if !strings.HasSuffix(s, unit.suffix) {
+			continue
+		}
+		num, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), 64)
+		if err != nil || num < 0 {
+			return 0, errors.Errorf("unexpected byte size (%v)", param)
+		}
+		return uint64(num * float64(unit.size)), nil
+	}
+	return 0, errors.Errorf("unexpected byte size (%v)", param)
+}
+
+func parseSetterInt(param string, bitSize int) (val int64, err error) {
+	val, err = strconv.ParseInt(param, 10, bitSize)
+	if err == nil {
+		return val, nil
+	}
+	size, sizeErr := parseByteSize(param)
+	if sizeErr != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strconv.FormatUint(size, 10), 10, bitSize)
+}
+
+func parseSetterUint(param string, bitSize int) (val uint64, err error) {
+	val, err = strconv.ParseUint(param, 10, bitSize)
+	if err == nil {
+		return val, nil
+	}
+	size, sizeErr := parseByteSize(param)
+	if sizeErr != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strconv.FormatUint(size, 10), 10, bitSize)
+}
+
+// splitSetterParam is split comma separated list. empty string is empty list.
+func splitSetterParam(param string) (elements []string) {
+	if strings.TrimSpace(param) == "" {
+		return []string{}
+	}
+	elements = strings.Split(param, ",")
+	for i, element := range elements {
+		elements[i] = strings.TrimSpace(element)
+	}
+	return elements
+}
+
+// parseSetterParam is convert setter parameter string to value of paramType.
+// slice is comma separated list (e.g. "a,b,c") and map is comma separated key=value list (e.g. "a=1,b=2").
+func parseSetterParam(param string, paramType reflect.Type) (value reflect.Value, err error) {
+	if parser, ok := getSetterParamParser(paramType); ok {
+		val, err := parser(param)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value = reflect.ValueOf(val)
+		if !value.IsValid() || !value.Type().ConvertibleTo(paramType) {
+			return reflect.Value{}, errors.Errorf("parser returns unexpected type (%v)", paramType)
+		}
+		return value.Convert(paramType), nil
+	}
+	switch paramType.Kind() {
+	case reflect.Bool:
+		val, err := strconv.ParseBool(param)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value = reflect.ValueOf(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val, err := parseSetterInt(param, paramType.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value = reflect.ValueOf(val)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val, err := parseSetterUint(param, paramType.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value = reflect.ValueOf(val)
+	case reflect.Float32, reflect.Float64:
+		val, err := strconv.ParseFloat(param, paramType.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value = reflect.ValueOf(val)
+	case reflect.String:
+		value = reflect.ValueOf(param)
+	case reflect.Slice:
+		elements := splitSetterParam(param)
+		value = reflect.MakeSlice(paramType, 0, len(elements))
+		for _, element := range elements {
+			elementValue, err := parseSetterParam(element, paramType.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value = reflect.Append(value, elementValue)
+		}
+		return value, nil
+	case reflect.Map:
+		elements := splitSetterParam(param)
+		value = reflect.MakeMapWithSize(paramType, len(elements))
+		for _, element := range elements {
+			idx := strings.Index(element, "=")
+			if idx < 0 {
+				return reflect.Value{}, errors.Errorf("unexpected element of map (%v)", element)
+			}
+			keyValue, err := parseSetterParam(strings.TrimSpace(element[:idx]), paramType.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			elementValue, err := parseSetterParam(strings.TrimSpace(element[idx+1:]), paramType.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value.SetMapIndex(keyValue, elementValue)
+		}
+		return value, nil
+	default:
+		return reflect.Value{}, errors.Errorf("unsupported kind of setter paramter (%v)", paramType.Kind())
+	}
+	return value.Convert(paramType), nil
+}
+
+func init() {
+	setterParamParsers = make(map[reflect.Type]SetterParamParser)
+	setterParamParsersMutex = new(sync.RWMutex)
+	RegisterSetterParamParser(reflect.TypeOf(time.Duration(0)), func(param string) (value interface{}, err error) {
+		return time.ParseDuration(strings.TrimSpace(param))
+	})
+	RegisterSetterParamParser(reflect.TypeOf(LogLevel(0)), func(param string) (value interface{}, err error) {
+		return ParseLogLevel(param)
+	})
+	RegisterSetterParamParser(reflect.TypeOf(ConsoleColor(0)), func(param string) (value interface{}, err error) {
+		return ParseConsoleColor(param)
+	})
+	RegisterSetterParamParser(reflect.TypeOf(ConsoleOutputType(0)), func(param string) (value interface{}, err error) {
+		return ParseConsoleOutputType(param)
+	})
+	RegisterSetterParamParser(reflect.TypeOf(OverflowPolicy(0)), func(param string) (value interface{}, err error) {
+		return ParseOverflowPolicy(param)
+	})
+}
@@ -0,0 +1,116 @@
+package belog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type setterParamTestTarget struct {
+	duration   time.Duration
+	size       int64
+	bufferSize uint
+	names      []string
+	levels     []LogLevel
+	labels     map[string]int
+	color      ConsoleColor
+	outputType ConsoleOutputType
+	logLevel   LogLevel
+	point      setterParamTestPoint
+}
+
+type setterParamTestPoint struct {
+	x int
+	y int
+}
+
+func (s *setterParamTestTarget) SetDuration(duration time.Duration) {
+	s.duration = duration
+}
+
+func (s *setterParamTestTarget) SetSize(size int64, bufferSize uint) {
+	s.size = size
+	s.bufferSize = bufferSize
+}
+
+func (s *setterParamTestTarget) SetNames(names []string, levels []LogLevel) {
+	s.names = names
+	s.levels = levels
+}
+
+func (s *setterParamTestTarget) SetLabels(labels map[string]int) {
+	s.labels = labels
+}
+
+func (s *setterParamTestTarget) SetEnums(color ConsoleColor, outputType ConsoleOutputType, logLevel LogLevel) {
+	s.color = color
+	s.outputType = outputType
+	s.logLevel = logLevel
+}
+
+func (s *setterParamTestTarget) SetPoint(point setterParamTestPoint) (err error) {
+	s.point = point
+	return nil
+}
+
+func TestSetupInstanceParamTypes(t *testing.T) {
+	RegisterSetterParamParser(reflect.TypeOf(setterParamTestPoint{}), func(param string) (value interface{}, err error) {
+		var point setterParamTestPoint
+		elements := strings.Split(param, ":")
+		point.x = len(elements[0])
+		point.y = len(elements[1])
+		return point, nil
+	})
+	target := new(setterParamTestTarget)
+	config := &configStruct{
+		StructSetters: []*configStructSetter{
+			{SetterName: "SetDuration", SetterParams: newConfigSetterParams("1m30s")},
+			{SetterName: "SetSize", SetterParams: newConfigSetterParams("64MiB", "4KB")},
+			{SetterName: "SetNames", SetterParams: newConfigSetterParams("a, b,c", "DEBUG,3")},
+			{SetterName: "SetLabels", SetterParams: newConfigSetterParams("x=1,y=2")},
+			{SetterName: "SetEnums", SetterParams: newConfigSetterParams("lightRed", "stderr", "notice")},
+			{SetterName: "SetPoint", SetterParams: newConfigSetterParams("ab:cde")},
+		},
+	}
+	if err := setupInstance(target, config, "target"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if target.duration != 90*time.Second {
+		t.Errorf("duration mismatch (%v)", target.duration)
+	}
+	if target.size != 64*1024*1024 || target.bufferSize != 4000 {
+		t.Errorf("size mismatch (%v, %v)", target.size, target.bufferSize)
+	}
+	if !reflect.DeepEqual(target.names, []string{"a", "b", "c"}) {
+		t.Errorf("names mismatch (%v)", target.names)
+	}
+	if !reflect.DeepEqual(target.levels, []LogLevel{LogLevelDebug, LogLevelCrit}) {
+		t.Errorf("levels mismatch (%v)", target.levels)
+	}
+	if !reflect.DeepEqual(target.labels, map[string]int{"x": 1, "y": 2}) {
+		t.Errorf("labels mismatch (%v)", target.labels)
+	}
+	if target.color != ConsoleColorLightRed || target.outputType != ConsoleOutputTypeStderr || target.logLevel != LogLevelNotice {
+		t.Errorf("enums mismatch (%v, %v, %v)", target.color, target.outputType, target.logLevel)
+	}
+	if target.point.x != 2 || target.point.y != 3 {
+		t.Errorf("point mismatch (%v)", target.point)
+	}
+
+	invalidConfigs := [][]string{
+		{"SetDuration", "90"},
+		{"SetEnums", "purple", "stderr", "info"},
+		{"SetLabels", "x"},
+	}
+	for _, invalidConfig := range invalidConfigs {
+		config := &configStruct{
+			StructSetters: []*configStructSetter{
+				{SetterName: invalidConfig[0], SetterParams: newConfigSetterParams(invalidConfig[1:]...)},
+			},
+		}
+		if err := setupInstance(target, config, "target"); err == nil {
+			t.Errorf("no error (%v)", invalidConfig)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package belog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	handler := setupAsyncLogger(t, "shutdown", OverflowPolicyBlock)
+	logger := GetLogger("shutdown")
+	logger.Info("1")
+	logger.Info("2")
+
+	// handler is blocked, so shutdown can not finish before deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("error mismatch (%v)", err)
+	}
+	close(handler.blocked)
+	if err := Shutdown(context.Background()); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if written := handler.getWritten(); len(written) != 2 {
+		t.Errorf("written mismatch (%v)", written)
+	}
+
+	// log events after shutdown are written to fallback logger
+	logger.Info("3")
+	if written := handler.getWritten(); len(written) != 2 {
+		t.Errorf("written mismatch (%v)", written)
+	}
+	if accepted := logger.Statistics().Accepted; accepted != 3 {
+		t.Errorf("accepted mismatch (%v)", accepted)
+	}
+
+	// restore default logger
+	if err := ChangeHandlers([]Handler{NewConsoleHandler()}); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+func TestShutdownStopsConfigWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "belog-watch")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer os.RemoveAll(dir)
+	configFilePath := filepath.Join(dir, "watch.json")
+	writeWatchConfig(t, configFilePath, "8")
+	watcher, err := WatchConfig(configFilePath, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := Shutdown(context.Background()); err != nil {
+		t.Errorf("%+v", err)
+	}
+	select {
+	case <-watcher.stopped:
+	default:
+		t.Errorf("config watcher is not stopped")
+	}
+	configWatchersMutex.Lock()
+	if _, ok := configWatchers[watcher]; ok {
+		t.Errorf("config watcher is not unregistered")
+	}
+	configWatchersMutex.Unlock()
+
+	// changed config is not applied after shutdown
+	writeWatchConfig(t, configFilePath, "4")
+	time.Sleep(50 * time.Millisecond)
+	if handler := lookupLogger("watch1").handlers[0]; handler.IsOpened() {
+		t.Errorf("handler is reopened")
+	}
+	watcher.Stop()
+
+	// restore default logger
+	if err := ChangeHandlers([]Handler{NewConsoleHandler()}); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
@@ -0,0 +1,158 @@
+//go:build go1.21
+
+package belog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+var (
+	slogLevelMap = map[LogLevel]slog.Level{
+		LogLevelEmerg:  slog.LevelError + 12,
+		LogLevelAlert:  slog.LevelError + 8,
+		LogLevelCrit:   slog.LevelError + 4,
+		LogLevelError:  slog.LevelError,
+		LogLevelWarn:   slog.LevelWarn,
+		LogLevelNotice: slog.LevelInfo + 2,
+		LogLevelInfo:   slog.LevelInfo,
+		LogLevelDebug:  slog.LevelDebug,
+		LogLevelTrace:  slog.LevelDebug - 4,
+	}
+)
+
+//SlogLevel is convert log level to level of log/slog
+func SlogLevel(logLevel LogLevel) (level slog.Level) {
+	level, ok := slogLevelMap[logLevel]
+	if !ok {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+//LogLevelFromSlog is convert level of log/slog to log level.
+//It returns the most verbose log level whose slog level is lower than or equal to level.
+func LogLevelFromSlog(level slog.Level) (logLevel LogLevel) {
+	for logLevel = LogLevelEmerg; logLevel < LogLevelTrace; logLevel++ {
+		if level >= slogLevelMap[logLevel] {
+			return logLevel
+		}
+	}
+	return LogLevelTrace
+}
+
+//SlogHandler is handler of log/slog. it routes slog records to named logger.
+type SlogHandler struct {
+	logger        *Logger
+	groupPrefix   string
+	keysAndValues []interface{}
+}
+
+//Enabled is check that level is enabled by named logger
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) (enabled bool) {
+	return h.logger.IsEnabled(LogLevelFromSlog(level))
+}
+
+//Handle is output slog record with named logger
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) (err error) {
+	logInfo := &logInfo{
+		program:  program,
+		pid:      pid,
+		hostname: hostname,
+		time:     record.Time,
+		logLevel: LogLevelFromSlog(record.Level),
+		message:  record.Message,
+	}
+	if logInfo.time.IsZero() {
+		logInfo.time = time.Now()
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		logInfo.pc = frame.PC
+		logInfo.fileName = frame.File
+		logInfo.lineNum = frame.Line
+	}
+	current := h.logger.current()
+	if record.PC != 0 && current.isStackTraceEnabled(logInfo.logLevel) {
+		logInfo.stackTrace = captureStackTraceFromPC(record.PC)
+	}
+	logInfo.err = h.logger.err
+	logInfo.setKeysAndValues(h.logger.keysAndValues)
+	logInfo.setKeysAndValues(extractContext(ctx))
+	logInfo.setKeysAndValues(h.keysAndValues)
+	keysAndValues := make([]interface{}, 0, record.NumAttrs()*2)
+	record.Attrs(func(attr slog.Attr) bool {
+		keysAndValues = appendSlogAttr(keysAndValues, h.groupPrefix, attr)
+		return true
+	})
+	logInfo.setKeysAndValues(keysAndValues)
+	current.log(h.logger.name, logInfo)
+	return nil
+}
+
+//WithAttrs is create SlogHandler with bound attributes
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) (handler slog.Handler) {
+	if len(attrs) == 0 {
+		return h
+	}
+	keysAndValues := make([]interface{}, 0, len(h.keysAndValues)+len(attrs)*2)
+	keysAndValues = append(keysAndValues, h.keysAndValues...)
+	for _, attr := range attrs {
+		keysAndValues = appendSlogAttr(keysAndValues, h.groupPrefix, attr)
+	}
+	return &SlogHandler{
+		logger:        h.logger,
+		groupPrefix:   h.groupPrefix,
+		keysAndValues: keysAndValues,
+	}
+}
+
+//WithGroup is create SlogHandler that qualifies keys of attributes by group name
+func (h *SlogHandler) WithGroup(name string) (handler slog.Handler) {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{
+		logger:        h.logger,
+		groupPrefix:   h.groupPrefix + name + ".",
+		keysAndValues: h.keysAndValues,
+	}
+}
+
+func appendSlogAttr(keysAndValues []interface{}, groupPrefix string, attr slog.Attr) []interface{} {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return keysAndValues
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			groupPrefix = groupPrefix + attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			keysAndValues = appendSlogAttr(keysAndValues, groupPrefix, groupAttr)
+		}
+		return keysAndValues
+	}
+	return append(keysAndValues, groupPrefix+attr.Key, attr.Value.Any())
+}
+
+//NewSlogHandler is create SlogHandler of named logger
+func NewSlogHandler(name string) (slogHandler *SlogHandler) {
+	return &SlogHandler{
+		logger: GetLogger(name),
+	}
+}
+
+//NewSlogLogger is create logger of log/slog that outputs with named logger
+func NewSlogLogger(name string) (slogLogger *slog.Logger) {
+	return slog.New(NewSlogHandler(name))
+}
+
+//Slog is create logger of log/slog that outputs with this named logger and its bound key value pairs
+func (l *Logger) Slog() (slogLogger *slog.Logger) {
+	return slog.New(&SlogHandler{
+		logger: l,
+	})
+}
@@ -0,0 +1,90 @@
+//go:build go1.21
+
+package belog
+
+import (
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogLevelFromSlog(t *testing.T) {
+	for logLevel := LogLevelEmerg; logLevel <= LogLevelTrace; logLevel++ {
+		if act := LogLevelFromSlog(SlogLevel(logLevel)); act != logLevel {
+			t.Errorf("log level mismatch (exp %v != act %v)", logLevel, act)
+		}
+	}
+	if act := LogLevelFromSlog(slog.LevelWarn + 1); act != LogLevelWarn {
+		t.Errorf("log level mismatch (exp %v != act %v)", LogLevelWarn, act)
+	}
+	if act := LogLevelFromSlog(slog.LevelDebug - 10); act != LogLevelTrace {
+		t.Errorf("log level mismatch (exp %v != act %v)", LogLevelTrace, act)
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	filter.SetLogLevel(LogLevelInfo)
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(shortFileName) %(message)%(attrs)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := SetLogger("slog", filter, formatter, []Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	logger := NewSlogLogger("slog").With("requestID", "abc").WithGroup("http")
+	logger.Info("test", "status", 200, slog.Group("req", "method", "GET"))
+	logger.Debug("test")
+	logger.Error("test")
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp := `datetime [INFO:7] slog slog_test.go test http.req.method=GET http.status=200 requestID=abc
+datetime [ERROR:4] slog slog_test.go test requestID=abc
+`
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+}
+
+func TestSlogHandlerStackTrace(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(message)%(stackTrace)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := SetLogger("slogStackTrace", filter, formatter, []Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := GetLogger("slogStackTrace").ChangeStackTraceLogLevel(LogLevelError); err != nil {
+		t.Errorf("%+v", err)
+	}
+	logger := NewSlogLogger("slogStackTrace")
+	logger.Warn("test")
+	logger.Error("test")
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	lines := strings.Split(string(b), "\n")
+	if len(lines) < 4 {
+		t.Fatalf("mismatch log (%v)", string(b))
+	}
+	if lines[0] != "datetime [WARN:5] slogStackTrace test" || lines[1] != "datetime [ERROR:4] slogStackTrace test" {
+		t.Errorf("mismatch log (%v)", string(b))
+	}
+	if lines[2] != "github.com/potix/belog.TestSlogHandlerStackTrace" || !strings.Contains(lines[3], "slog_test.go") {
+		t.Errorf("mismatch stack trace (%v)", string(b))
+	}
+}
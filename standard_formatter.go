@@ -1,7 +1,9 @@
 package belog
 
 import (
+	"bytes"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,9 +24,7 @@ func (f *StandardFormatter) Format(loggerName string, log LogEvent) (formattedLo
 	defer f.mutex.RUnlock()
 	logMessage := log.Message()
 	if f.appendNewLine {
-		if len(logMessage) == 0 || logMessage[len(logMessage) - 1:] != "\n" {
-			logMessage = logMessage + "\n"
-		}
+		logMessage = strings.TrimSuffix(logMessage, "\n")
 	}
 	replacer := strings.NewReplacer(
 		"%(dateTime)", log.Time().Format(f.dateTimeLayout),
@@ -38,11 +38,20 @@ func (f *StandardFormatter) Format(loggerName string, log LogEvent) (formattedLo
 		"%(fileName)", log.FileName(),
 		"%(shortFileName)", filepath.Base(log.FileName()),
 		"%(lineNum)", strconv.Itoa(log.LineNum()),
-		"%(message)", logMessage)
-	return replacer.Replace(f.layout), nil
+		"%(message)", logMessage,
+		"%(attrs)", formatAttrs(log.GetAttrs()),
+		"%(error)", formatErrorInfo(log.Err()),
+		"%(stackTrace)", formatStackTrace(log.StackTrace()))
+	formattedLog = replacer.Replace(f.layout)
+	if f.appendNewLine {
+		formattedLog = formattedLog + "\n"
+	}
+	return formattedLog, nil
 }
 
 //SetAppendNewLine is set append new line.
+//New line is appended to the end of formatted log instead of message, so tags after %(message) (e.g. %(attrs)) are output in the same line.
+//One trailing new line of message is removed, because it is regarded as the end of line.
 func (f *StandardFormatter) SetAppendNewLine(appendNewLine bool) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
@@ -70,24 +79,73 @@ func (f *StandardFormatter) SetDateTimeLayout(dateTimeLayout string) {
 //   %(shortFileName)  : short file name (basename only)
 //   %(lineNum)        : line number
 //   %(message)        : message
+//   %(attrs)          : attributes as " key=value" pairs sorted by key
+//   %(error)          : attached error, its causes and stack trace as indented block preceded by new line
+//   %(stackTrace)     : stack trace preceded by new line if captured
 func (f *StandardFormatter) SetLayout(layout string) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 	f.layout = layout
 }
 
+//ExportSetters is export current settings as setters of config
+func (f *StandardFormatter) ExportSetters() (setters []ConfigSetter) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return []ConfigSetter{
+		{SetterName: "SetAppendNewLine", SetterParams: []interface{}{strconv.FormatBool(f.appendNewLine)}},
+		{SetterName: "SetDateTimeLayout", SetterParams: []interface{}{f.dateTimeLayout}},
+		{SetterName: "SetLayout", SetterParams: []interface{}{f.layout}},
+	}
+}
+
 //NewStandardFormatter is create StandardFormatter
 func NewStandardFormatter() (standardFormatter *StandardFormatter) {
 	return &StandardFormatter{
 		appendNewLine:  true,
 		dateTimeLayout: "2006-01-02 15:04:05",
-		layout:         "%(dateTime) [%(logLevel)] (%(pid)) %(program) %(loggerName) %(fileName) %(lineNum) %(message)",
+		layout:         "%(dateTime) [%(logLevel)] (%(pid)) %(program) %(loggerName) %(fileName) %(lineNum) %(message)%(attrs)%(error)%(stackTrace)",
 		mutex:          new(sync.RWMutex),
 	}
 }
 
+func formatStackTrace(stackTrace string) (formattedStackTrace string) {
+	if stackTrace == "" {
+		return ""
+	}
+	return "\n" + strings.TrimSuffix(stackTrace, "\n")
+}
+
+func formatAttrs(attrs map[string]interface{}) (formattedAttrs string) {
+	if len(attrs) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	buffer := new(bytes.Buffer)
+	for _, key := range keys {
+		buffer.WriteString(" ")
+		buffer.WriteString(key)
+		buffer.WriteString("=")
+		buffer.WriteString(formatAttrValue(attrs[key]))
+	}
+	return buffer.String()
+}
+
+func formatAttrValue(value interface{}) (formattedValue string) {
+	formattedValue = attrText(value)
+	if formattedValue == "" || strings.ContainsAny(formattedValue, " \t\r\n\"=") {
+		return strconv.Quote(formattedValue)
+	}
+	return formattedValue
+}
+
 func init() {
 	RegisterFormatter("StandardFormatter", func() (formatter Formatter) {
 		return NewStandardFormatter()
 	})
+	recordComponentType("StandardFormatter", (*StandardFormatter)(nil))
 }
@@ -0,0 +1,33 @@
+package belog
+
+import (
+	"testing"
+)
+
+func TestStandardFormatterAppendNewLine(t *testing.T) {
+	formatter := NewStandardFormatter()
+	formatter.SetLayout("%(message) end")
+	testCases := map[string]string{
+		"a":     "a end\n",
+		"a\n":   "a end\n",
+		"a\n\n": "a\n end\n",
+		"":      " end\n",
+	}
+	for message, expected := range testCases {
+		formattedLog, err := formatter.Format("test", &logInfo{message: message})
+		if err != nil {
+			t.Errorf("%+v", err)
+		}
+		if formattedLog != expected {
+			t.Errorf("mismatch log (%q: exp %q != act %q)", message, expected, formattedLog)
+		}
+	}
+	formatter.SetAppendNewLine(false)
+	formattedLog, err := formatter.Format("test", &logInfo{message: "a\n"})
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	if formattedLog != "a\n end" {
+		t.Errorf("mismatch log (%q)", formattedLog)
+	}
+}
@@ -0,0 +1,111 @@
+package belog
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+//LoggerStatistics is snapshot of statistics of logger
+type LoggerStatistics struct {
+	Accepted     uint64
+	Filtered     uint64
+	FormatErrors uint64
+	Dropped      uint64
+	Handlers     []HandlerStatistics
+}
+
+//HandlerStatistics is snapshot of statistics of handler
+type HandlerStatistics struct {
+	HandlerName  string
+	Writes       uint64
+	WriteErrors  uint64
+	Dropped      uint64
+	BytesWritten uint64
+	Rotations    uint64
+	Reconnects   uint64
+}
+
+//StatisticsHandler is optional interface of handler to report statistics
+type StatisticsHandler interface {
+	Statistics() (statistics HandlerStatistics)
+}
+
+type loggerCounters struct {
+	accepted     uint64
+	filtered     uint64
+	formatErrors uint64
+	dropped      uint64
+}
+
+func (c *loggerCounters) snapshot() (statistics LoggerStatistics) {
+	return LoggerStatistics{
+		Accepted:     atomic.LoadUint64(&c.accepted),
+		Filtered:     atomic.LoadUint64(&c.filtered),
+		FormatErrors: atomic.LoadUint64(&c.formatErrors),
+		Dropped:      atomic.LoadUint64(&c.dropped),
+	}
+}
+
+type handlerCounters struct {
+	writes       uint64
+	writeErrors  uint64
+	dropped      uint64
+	bytesWritten uint64
+	rotations    uint64
+	reconnects   uint64
+}
+
+func (c *handlerCounters) write(bytesWritten int) {
+	atomic.AddUint64(&c.writes, 1)
+	atomic.AddUint64(&c.bytesWritten, uint64(bytesWritten))
+}
+
+func (c *handlerCounters) writeError() {
+	atomic.AddUint64(&c.writeErrors, 1)
+}
+
+func (c *handlerCounters) drop() {
+	atomic.AddUint64(&c.dropped, 1)
+}
+
+func (c *handlerCounters) rotate() {
+	atomic.AddUint64(&c.rotations, 1)
+}
+
+func (c *handlerCounters) reconnect() {
+	atomic.AddUint64(&c.reconnects, 1)
+}
+
+func (c *handlerCounters) snapshot(handlerName string) (statistics HandlerStatistics) {
+	return HandlerStatistics{
+		HandlerName:  handlerName,
+		Writes:       atomic.LoadUint64(&c.writes),
+		WriteErrors:  atomic.LoadUint64(&c.writeErrors),
+		Dropped:      atomic.LoadUint64(&c.dropped),
+		BytesWritten: atomic.LoadUint64(&c.bytesWritten),
+		Rotations:    atomic.LoadUint64(&c.rotations),
+		Reconnects:   atomic.LoadUint64(&c.reconnects),
+	}
+}
+
+func handlerStatistics(handler Handler) (statistics HandlerStatistics) {
+	statisticsHandler, ok := handler.(StatisticsHandler)
+	if !ok {
+		return HandlerStatistics{
+			HandlerName: fmt.Sprintf("%T", handler),
+		}
+	}
+	return statisticsHandler.Statistics()
+}
+
+//GetStatistics is get statistics of default logger and all loggers
+func GetStatistics() (statistics map[string]LoggerStatistics) {
+	statistics = make(map[string]LoggerStatistics)
+	statistics["default"] = defaultLogger.statistics()
+	loggersMutex.RLock()
+	defer loggersMutex.RUnlock()
+	for name, logger := range loggers {
+		statistics[name] = logger.statistics()
+	}
+	return statistics
+}
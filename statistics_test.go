@@ -0,0 +1,40 @@
+package belog
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStatistics(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	filter.SetLogLevel(LogLevelWarn)
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(message)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := SetLogger("statistics", filter, formatter, []Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	logger := GetLogger("statistics")
+	logger.Error("test")
+	logger.Warn("test")
+	logger.Info("test")
+	statistics := GetStatistics()["statistics"]
+	if statistics.Accepted != 2 || statistics.Filtered != 1 || statistics.FormatErrors != 0 {
+		t.Errorf("logger statistics mismatch (%+v)", statistics)
+	}
+	if len(statistics.Handlers) != 1 {
+		t.Fatalf("handler statistics mismatch (%+v)", statistics)
+	}
+	handlerStatistics := statistics.Handlers[0]
+	if handlerStatistics.HandlerName != "RotationFileHandler" || handlerStatistics.Writes != 2 || handlerStatistics.BytesWritten != 10 {
+		t.Errorf("handler statistics mismatch (%+v)", handlerStatistics)
+	}
+	if logger.Statistics().Accepted != 2 {
+		t.Errorf("logger statistics mismatch (%+v)", logger.Statistics())
+	}
+}
@@ -1,6 +1,7 @@
 package belog
 
 import (
+	"github.com/pkg/errors"
 	"log/syslog"
 	"os"
 	"path/filepath"
@@ -41,6 +42,8 @@ type SyslogHandler struct {
 	facility   syslog.Priority
 	writer     *syslog.Writer
 	reopenable bool
+	closed     chan struct{}
+	counters   *handlerCounters
 	mutex      *sync.RWMutex
 }
 
@@ -63,9 +66,17 @@ func (h *SyslogHandler) Open() {
 		return
 	}
 	h.reopenable = true
+	if h.closed == nil {
+		h.closed = make(chan struct{})
+	}
+	h.dial()
+}
+
+func (h *SyslogHandler) dial() {
 	writer, err := syslog.Dial(h.network, h.addr, h.facility, h.tag)
 	if err != nil {
-		go h.reopenSyslog()
+		ReportError("", nil, "SyslogHandler", err)
+		go h.reopenSyslog(h.closed)
 	} else {
 		h.writer = writer
 	}
@@ -76,47 +87,49 @@ func (h *SyslogHandler) Write(loggerName string, logEvent LogEvent, formattedLog
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
 	if h.writer == nil {
-		// statistics
+		h.counters.drop()
+		ReportError(loggerName, logEvent, "SyslogHandler", errors.Errorf("syslog is not opened"))
 		return
 	}
+	var err error
 	switch logEvent.LogLevelNum() {
 	case LogLevelEmerg:
-		if err := h.writer.Emerg(formattedLog); err != nil {
-			// statistics
-		}
+		err = h.writer.Emerg(formattedLog)
 	case LogLevelAlert:
-		if err := h.writer.Alert(formattedLog); err != nil {
-			// statistics
-		}
+		err = h.writer.Alert(formattedLog)
 	case LogLevelCrit:
-		if err := h.writer.Crit(formattedLog); err != nil {
-			// statistics
-		}
+		err = h.writer.Crit(formattedLog)
 	case LogLevelError:
-		if err := h.writer.Err(formattedLog); err != nil {
-			// statistics
-		}
+		err = h.writer.Err(formattedLog)
 	case LogLevelWarn:
-		if err := h.writer.Warning(formattedLog); err != nil {
-			// statistics
-		}
+		err = h.writer.Warning(formattedLog)
 	case LogLevelNotice:
-		if err := h.writer.Notice(formattedLog); err != nil {
-			// statistics
-		}
+		err = h.writer.Notice(formattedLog)
 	case LogLevelInfo:
-		if err := h.writer.Info(formattedLog); err != nil {
-			// statistics
-		}
+		err = h.writer.Info(formattedLog)
 	case LogLevelDebug:
 		fallthrough
 	case LogLevelTrace:
-		if err := h.writer.Debug(formattedLog); err != nil {
-			// statistics
-		}
+		err = h.writer.Debug(formattedLog)
 	default:
-		// statistics
+		h.counters.drop()
+		return
+	}
+	if err != nil {
+		h.writeError(loggerName, logEvent, err)
+		return
 	}
+	h.counters.write(len(formattedLog))
+}
+
+func (h *SyslogHandler) writeError(loggerName string, logEvent LogEvent, err error) {
+	h.counters.writeError()
+	ReportError(loggerName, logEvent, "SyslogHandler", err)
+}
+
+//Statistics is get statistics of handler
+func (h *SyslogHandler) Statistics() (statistics HandlerStatistics) {
+	return h.counters.snapshot("SyslogHandler")
 }
 
 //Flush is call nothing to do
@@ -128,11 +141,15 @@ func (h *SyslogHandler) Close() {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 	h.reopenable = false
+	if h.closed != nil {
+		close(h.closed)
+		h.closed = nil
+	}
 	if h.writer == nil {
 		return
 	}
 	if err := h.writer.Close(); err != nil {
-		// statistics
+		h.writeError("", nil, err)
 	}
 	h.writer = nil
 }
@@ -141,20 +158,19 @@ func (h *SyslogHandler) Close() {
 func (h *SyslogHandler) SetNetworkAndAddr(network string, addr string) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
-	if h.network != network || h.addr != addr {
-		if err := h.writer.Close(); err != nil {
-			// statistics
-		}
-		h.writer = nil
-		writer, err := syslog.Dial(h.network, h.addr, h.facility, h.tag)
-		if err != nil {
-			go h.reopenSyslog()
-		} else {
-			h.writer = writer
-		}
+	if h.network == network && h.addr == addr {
+		return
 	}
 	h.network = network
 	h.addr = addr
+	if h.writer == nil {
+		return
+	}
+	if err := h.writer.Close(); err != nil {
+		h.writeError("", nil, err)
+	}
+	h.writer = nil
+	h.dial()
 }
 
 //SetTag is set tag
@@ -175,14 +191,39 @@ func (h *SyslogHandler) SetFacility(facility string) {
 	h.facility = fac
 }
 
-func (h *SyslogHandler) reopenSyslog() {
+func (h *SyslogHandler) reopenSyslog(closed chan struct{}) {
 	// retry Open
-	time.Sleep(time.Second)
+	select {
+	case <-time.After(time.Second):
+	case <-closed:
+		return
+	}
 	h.mutex.RLock()
 	reopenable := h.reopenable
 	h.mutex.RUnlock()
 	if reopenable {
 		h.Open()
+		if h.IsOpened() {
+			h.counters.reconnect()
+		}
+	}
+}
+
+//ExportSetters is export current settings as setters of config
+func (h *SyslogHandler) ExportSetters() (setters []ConfigSetter) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	facility := "LOCAL0"
+	for name, fac := range facilityMap {
+		if fac == h.facility {
+			facility = name
+			break
+		}
+	}
+	return []ConfigSetter{
+		{SetterName: "SetNetworkAndAddr", SetterParams: []interface{}{h.network, h.addr}},
+		{SetterName: "SetTag", SetterParams: []interface{}{h.tag}},
+		{SetterName: "SetFacility", SetterParams: []interface{}{facility}},
 	}
 }
 
@@ -193,6 +234,7 @@ func NewSyslogHandler() (syslogHandler *SyslogHandler) {
 		addr:     "",
 		tag:      filepath.Base(os.Args[0]),
 		facility: syslog.LOG_LOCAL0,
+		counters: new(handlerCounters),
 		mutex:    new(sync.RWMutex),
 	}
 }
@@ -201,4 +243,5 @@ func init() {
 	RegisterHandler("SyslogHandler", func() (handler Handler) {
 		return NewSyslogHandler()
 	})
+	recordComponentType("SyslogHandler", (*SyslogHandler)(nil))
 }
@@ -0,0 +1,150 @@
+package belog
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	logWriterSkipPrefixes []string
+)
+
+//LogWriter is io.Writer that outputs each line with named logger at fixed log level
+type LogWriter struct {
+	logger   *Logger
+	logLevel LogLevel
+	buffer   *bytes.Buffer
+	mutex    *sync.Mutex
+}
+
+//Write is output complete lines of p. incomplete line is buffered until next new line or Flush.
+func (w *LogWriter) Write(p []byte) (n int, err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.buffer.Write(p)
+	for {
+		idx := bytes.IndexByte(w.buffer.Bytes(), '\n')
+		if idx < 0 {
+			break
+		}
+		line := string(w.buffer.Next(idx + 1))
+		w.writeLine(line[:idx])
+	}
+	return len(p), nil
+}
+
+//Flush is output buffered incomplete line
+func (w *LogWriter) Flush() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.buffer.Len() == 0 {
+		return
+	}
+	w.writeLine(w.buffer.String())
+	w.buffer.Reset()
+}
+
+//Close is output buffered incomplete line
+func (w *LogWriter) Close() (err error) {
+	w.Flush()
+	return nil
+}
+
+func (w *LogWriter) writeLine(line string) {
+	line = strings.TrimSuffix(line, "\r")
+	if line == "" {
+		return
+	}
+	current := w.logger.current()
+	if !current.isEnabled(w.logger.name, w.logLevel) {
+		current.countFiltered()
+		return
+	}
+	logInfo := &logInfo{
+		program:  program,
+		pid:      pid,
+		hostname: hostname,
+		time:     time.Now(),
+		logLevel: w.logLevel,
+		message:  line,
+	}
+	callers := logWriterCallers()
+	if len(callers) > 0 {
+		frame, _ := runtime.CallersFrames(callers[:1]).Next()
+		logInfo.pc = frame.PC
+		logInfo.fileName = frame.File
+		logInfo.lineNum = frame.Line
+	}
+	if current.isStackTraceEnabled(w.logLevel) {
+		logInfo.stackTrace = stackTraceFromCallers(callers)
+	}
+	logInfo.err = w.logger.err
+	logInfo.setKeysAndValues(w.logger.keysAndValues)
+	current.log(w.logger.name, logInfo)
+}
+
+// logWriterCallers is get callers from first caller outside of LogWriter and writer packages of standard library
+func logWriterCallers() (callers []uintptr) {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	for i := 0; i < n; i++ {
+		frame, _ := runtime.CallersFrames(pcs[i : i+1]).Next()
+		skip := false
+		for _, prefix := range logWriterSkipPrefixes {
+			if strings.HasPrefix(frame.Function, prefix) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			return pcs[i:n]
+		}
+	}
+	return nil
+}
+
+//NewLogWriter is create LogWriter of named logger
+func NewLogWriter(name string, logLevel LogLevel) (logWriter *LogWriter) {
+	return &LogWriter{
+		logger:   GetLogger(name),
+		logLevel: logLevel,
+		buffer:   new(bytes.Buffer),
+		mutex:    new(sync.Mutex),
+	}
+}
+
+//NewStdLogger is create *log.Logger that outputs with named logger at fixed log level
+func NewStdLogger(name string, logLevel LogLevel) (stdLogger *log.Logger) {
+	return log.New(NewLogWriter(name, logLevel), "", 0)
+}
+
+//RedirectStdLog is redirect output of standard log package to named logger at fixed log level.
+//restore is function to restore output, prefix and flags of standard log package.
+func RedirectStdLog(name string, logLevel LogLevel) (restore func()) {
+	oldWriter := log.Writer()
+	oldPrefix := log.Prefix()
+	oldFlags := log.Flags()
+	log.SetOutput(NewLogWriter(name, logLevel))
+	log.SetPrefix("")
+	log.SetFlags(0)
+	return func() {
+		log.SetOutput(oldWriter)
+		log.SetPrefix(oldPrefix)
+		log.SetFlags(oldFlags)
+	}
+}
+
+func init() {
+	logWriterSkipPrefixes = []string{
+		reflect.TypeOf(LogWriter{}).PkgPath() + ".(*LogWriter).",
+		"log.",
+		"fmt.",
+		"io.",
+		"bufio.",
+	}
+}
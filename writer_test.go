@@ -0,0 +1,80 @@
+package belog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogWriter(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(shortFileName) %(message)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := SetLogger("writer", filter, formatter, []Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	writer := NewLogWriter("writer", LogLevelWarn)
+	fmt.Fprintf(writer, "test1\ntest2\n\ntes")
+	fmt.Fprintf(writer, "t3")
+	writer.Flush()
+	NewStdLogger("writer", LogLevelError).Printf("test4")
+	restore := RedirectStdLog("writer", LogLevelInfo)
+	log.Println("test5")
+	restore()
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	exp := `datetime [WARN:5] writer writer_test.go test1
+datetime [WARN:5] writer writer_test.go test2
+datetime [WARN:5] writer writer_test.go test3
+datetime [ERROR:4] writer writer_test.go test4
+datetime [INFO:7] writer writer_test.go test5
+`
+	if exp != string(b) {
+		t.Errorf("mismatch log (exp %v != act %v)", exp, string(b))
+	}
+}
+
+func TestLogWriterStackTrace(t *testing.T) {
+	os.RemoveAll("/var/tmp/belog-test")
+	filter := NewLogLevelFilter()
+	formatter := NewStandardFormatter()
+	formatter.SetDateTimeLayout("datetime")
+	formatter.SetLayout("%(dateTime) [%(logLevel):%(logLevelNum)] %(loggerName) %(message)%(stackTrace)")
+	handler1 := NewRotationFileHandler()
+	handler1.SetLogFileName("belog-test.log")
+	handler1.SetLogDirPath("/var/tmp/belog-test")
+	handler1.SetAsync(false)
+	if err := SetLogger("writerStackTrace", filter, formatter, []Handler{handler1}); err != nil {
+		t.Errorf("%+v", err)
+	}
+	if err := GetLogger("writerStackTrace").ChangeStackTraceLogLevel(LogLevelError); err != nil {
+		t.Errorf("%+v", err)
+	}
+	fmt.Fprintln(NewLogWriter("writerStackTrace", LogLevelWarn), "test1")
+	NewStdLogger("writerStackTrace", LogLevelError).Printf("test2")
+	b, err := ioutil.ReadFile("/var/tmp/belog-test/belog-test.log")
+	if err != nil {
+		t.Errorf("%+v", err)
+	}
+	lines := strings.Split(string(b), "\n")
+	if len(lines) < 4 {
+		t.Fatalf("mismatch log (%v)", string(b))
+	}
+	if lines[0] != "datetime [WARN:5] writerStackTrace test1" || lines[1] != "datetime [ERROR:4] writerStackTrace test2" {
+		t.Errorf("mismatch log (%v)", string(b))
+	}
+	if lines[2] != "github.com/potix/belog.TestLogWriterStackTrace" || !strings.Contains(lines[3], "writer_test.go") {
+		t.Errorf("mismatch stack trace (%v)", string(b))
+	}
+}